@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/tunnel"
+)
+
+const (
+	DefaultAddr = "127.0.0.1:18964"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+)
+
+var (
+	server *http.Server
+	mu     sync.Mutex
+)
+
+// Start starts the control API if it is enabled in the config, the running API is stopped first.
+// The API only listens on a loopback address, it can change the tunnels exposing the local services.
+func Start() error {
+	Stop()
+
+	cfg := config.Get()
+	if cfg.API == nil || cfg.API.Addr == "" {
+		return nil
+	}
+	if err := tunnel.ValidateLoopback(cfg.API.Addr); err != nil {
+		return err
+	}
+
+	if cfg.API.Token == "" {
+		token, err := generateToken()
+		if err != nil {
+			return err
+		}
+		cfg.API.Token = token
+		config.Set(cfg)
+		if err := cfg.Write(); err != nil {
+			return err
+		}
+	}
+
+	ln, err := net.Listen("tcp", cfg.API.Addr)
+	if err != nil {
+		return err
+	}
+
+	log := logger.Default().WithFields(map[string]any{
+		"kind": "api",
+	})
+
+	srv := &http.Server{
+		Handler:           authHandler(cfg.API.Token, newHandler()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	mu.Lock()
+	server = srv
+	mu.Unlock()
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error(err)
+		}
+	}()
+	log.Infof("control API listen on %s", ln.Addr())
+
+	return nil
+}
+
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	server.Shutdown(ctx)
+	server = nil
+}
+
+func authHandler(token string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(v), []byte(token)) != 1 {
+			writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type errorResponse struct {
+	Msg string `json:"msg"`
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Msg: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
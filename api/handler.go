@@ -0,0 +1,475 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-gost/gost.plus/config"
+	tunnel_server "github.com/go-gost/gost.plus/server"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/google/uuid"
+)
+
+type Tunnel struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	Type       string              `json:"type"`
+	Endpoint   string              `json:"endpoint"`
+	Strategy   string              `json:"strategy,omitempty"`
+	Routes     []config.HTTPRoute  `json:"routes,omitempty"`
+	ReqHeaders []config.HTTPHeader `json:"requestHeaders,omitempty"`
+	ResHeaders []config.HTTPHeader `json:"responseHeaders,omitempty"`
+	Entrypoint string              `json:"entrypoint"`
+	Transport  config.Transport    `json:"transport"`
+	Proxies    []config.Proxy      `json:"proxies,omitempty"`
+	Hostname   string              `json:"hostname,omitempty"`
+	Username   string              `json:"username,omitempty"`
+	EnableTLS  bool                `json:"enableTLS,omitempty"`
+	TLS        *config.TLS         `json:"tls,omitempty"`
+	Keepalive  bool                `json:"keepalive,omitempty"`
+	TTL        int                 `json:"ttl,omitempty"`
+	Config     string              `json:"config,omitempty"`
+	Favorite   bool                `json:"favorite"`
+	Closed     bool                `json:"closed"`
+	Err        string              `json:"err,omitempty"`
+	BackendErr string              `json:"backendErr,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	Stats      config.ServiceStats `json:"stats"`
+	Retry      *Retry              `json:"retry,omitempty"`
+	Health     *Health             `json:"health,omitempty"`
+	Backends   []Backend           `json:"backends,omitempty"`
+}
+
+type Backend struct {
+	Addr         string `json:"addr"`
+	Down         bool   `json:"down"`
+	CurrentConns uint64 `json:"currentConns"`
+	TotalConns   uint64 `json:"totalConns"`
+	TotalErrs    uint64 `json:"totalErrs"`
+	InputBytes   uint64 `json:"inputBytes"`
+	OutputBytes  uint64 `json:"outputBytes"`
+}
+
+type Health struct {
+	State       string    `json:"state"`
+	Latency     int64     `json:"latencyMs"`
+	ServerErr   string    `json:"serverErr,omitempty"`
+	EndpointErr string    `json:"endpointErr,omitempty"`
+	CheckedAt   time.Time `json:"checkedAt"`
+	Since       time.Time `json:"since"`
+}
+
+type Retry struct {
+	Attempts int        `json:"attempts"`
+	Err      string     `json:"err,omitempty"`
+	Next     *time.Time `json:"next,omitempty"`
+}
+
+// TunnelRequest is the body of the create and update requests,
+// the omitted fields keep their current values on update.
+type TunnelRequest struct {
+	ID         *string              `json:"id"`
+	Type       *string              `json:"type"`
+	Name       *string              `json:"name"`
+	Endpoint   *string              `json:"endpoint"`
+	Strategy   *string              `json:"strategy"`
+	Routes     *[]config.HTTPRoute  `json:"routes"`
+	ReqHeaders *[]config.HTTPHeader `json:"requestHeaders"`
+	ResHeaders *[]config.HTTPHeader `json:"responseHeaders"`
+	Hostname   *string              `json:"hostname"`
+	Username   *string              `json:"username"`
+	Password   *string              `json:"password"`
+	EnableTLS  *bool                `json:"enableTLS"`
+	TLS        *config.TLS          `json:"tls"`
+	Keepalive  *bool                `json:"keepalive"`
+	TTL        *int                 `json:"ttl"`
+	Transport  *config.Transport    `json:"transport"`
+	Proxies    *[]config.Proxy      `json:"proxies"`
+	Config     *string              `json:"config"`
+}
+
+func (r *TunnelRequest) apply(opts *tunnel.Options) {
+	if r.Name != nil {
+		opts.Name = *r.Name
+	}
+	if r.Endpoint != nil {
+		opts.Endpoint = *r.Endpoint
+	}
+	if r.Strategy != nil {
+		opts.Strategy = *r.Strategy
+	}
+	if r.Routes != nil {
+		opts.Routes = *r.Routes
+	}
+	if r.ReqHeaders != nil {
+		opts.RequestHeaders = *r.ReqHeaders
+	}
+	if r.ResHeaders != nil {
+		opts.ResponseHeaders = *r.ResHeaders
+	}
+	if r.Hostname != nil {
+		opts.Hostname = *r.Hostname
+	}
+	if r.Username != nil {
+		opts.Username = *r.Username
+	}
+	if r.Password != nil {
+		opts.Password = *r.Password
+	}
+	if r.EnableTLS != nil {
+		opts.EnableTLS = *r.EnableTLS
+	}
+	if r.TLS != nil {
+		opts.TLS = r.TLS
+	}
+	if r.Keepalive != nil {
+		opts.Keepalive = *r.Keepalive
+	}
+	if r.TTL != nil {
+		opts.TTL = *r.TTL
+	}
+	if r.Transport != nil {
+		opts.Transport = r.Transport
+	}
+	if r.Proxies != nil {
+		opts.Proxies = *r.Proxies
+	}
+	if r.Config != nil {
+		opts.Config = *r.Config
+	}
+}
+
+// registry adapts the tunnel and entrypoint packages to the same handlers.
+type registry struct {
+	count    func() int
+	getIndex func(index int) tunnel.Tunnel
+	get      func(id string) tunnel.Tunnel
+	add      func(t tunnel.Tunnel)
+	set      func(t tunnel.Tunnel)
+	delete   func(id string)
+	save     func() error
+	build    func(st string, opts tunnel.Options) tunnel.Tunnel
+	validate func(st string, opts tunnel.Options) error
+	retry    func(id string) (tunnel.RetryState, bool)
+	// health is nil for the entrypoints which are not health checked.
+	health func(id string) (tunnel.Health, bool)
+	// the entrypoint ID must be the ID of an existing tunnel.
+	requireID bool
+}
+
+var (
+	tunnels = &registry{
+		count:    tunnel.Count,
+		getIndex: tunnel.GetIndex,
+		get:      tunnel.Get,
+		add:      tunnel.Add,
+		set:      tunnel.Set,
+		delete:   tunnel.Delete,
+		save:     tunnel.SaveConfig,
+		build:    tunnel.NewTunnel,
+		validate: tunnel.ValidateOptions,
+		retry:    tunnel.Retry,
+		health:   tunnel.GetHealth,
+	}
+	entrypoints = &registry{
+		count:     entrypoint.Count,
+		getIndex:  entrypoint.GetIndex,
+		get:       entrypoint.Get,
+		add:       entrypoint.Add,
+		set:       entrypoint.Set,
+		delete:    entrypoint.Delete,
+		save:      entrypoint.SaveConfig,
+		build:     entrypoint.NewEntryPoint,
+		validate:  entrypoint.ValidateOptions,
+		retry:     entrypoint.Retry,
+		requireID: true,
+	}
+)
+
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	for prefix, reg := range map[string]*registry{
+		"/api/tunnels":     tunnels,
+		"/api/entrypoints": entrypoints,
+	} {
+		mux.HandleFunc("GET "+prefix, reg.list)
+		mux.HandleFunc("POST "+prefix, reg.create)
+		mux.HandleFunc("GET "+prefix+"/{id}", reg.getOne)
+		mux.HandleFunc("PUT "+prefix+"/{id}", reg.update)
+		mux.HandleFunc("DELETE "+prefix+"/{id}", reg.remove)
+		mux.HandleFunc("POST "+prefix+"/{id}/start", reg.start)
+		mux.HandleFunc("POST "+prefix+"/{id}/stop", reg.stop)
+		mux.HandleFunc("GET "+prefix+"/{id}/stats", reg.stats)
+	}
+	mux.HandleFunc("GET /api/server/tunnels", serverTunnels)
+	return mux
+}
+
+// serverTunnels lists the tunnels connected to the built-in tunnel server.
+func serverTunnels(w http.ResponseWriter, r *http.Request) {
+	list := tunnel_server.Tunnels()
+	if list == nil {
+		list = []tunnel_server.Tunnel{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (reg *registry) list(w http.ResponseWriter, r *http.Request) {
+	list := []Tunnel{}
+	for i := 0; i < reg.count(); i++ {
+		if t := reg.getIndex(i); t != nil {
+			list = append(list, reg.convert(t))
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (reg *registry) getOne(w http.ResponseWriter, r *http.Request) {
+	t := reg.get(r.PathValue("id"))
+	if t == nil {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, reg.convert(t))
+}
+
+func (reg *registry) stats(w http.ResponseWriter, r *http.Request) {
+	t := reg.get(r.PathValue("id"))
+	if t == nil {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, t.Stats())
+}
+
+func (reg *registry) create(w http.ResponseWriter, r *http.Request) {
+	var req TunnelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Type == nil {
+		writeError(w, http.StatusBadRequest, errors.New("type is required"))
+		return
+	}
+
+	var opts tunnel.Options
+	if req.ID != nil {
+		id, err := uuid.Parse(*req.ID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid ID: %w", err))
+			return
+		}
+		opts.ID = id.String()
+	} else if reg.requireID {
+		writeError(w, http.StatusBadRequest, errors.New("id is required"))
+		return
+	}
+	if opts.ID != "" && reg.get(opts.ID) != nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("%s already exists", opts.ID))
+		return
+	}
+	req.apply(&opts)
+	if err := reg.validate(*req.Type, opts); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	t := reg.build(*req.Type, opts)
+	if t == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown type %s", *req.Type))
+		return
+	}
+
+	defer reg.save()
+
+	reg.add(t)
+	if err := t.Run(); err != nil {
+		t.Close()
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reg.convert(t))
+}
+
+func (reg *registry) update(w http.ResponseWriter, r *http.Request) {
+	old := reg.get(r.PathValue("id"))
+	if old == nil {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+
+	var req TunnelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	opts := old.Options()
+	opts.Stats = old.Stats()
+	req.apply(&opts)
+	if err := reg.validate(old.Type(), opts); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	t, err := reg.restart(old, opts, !old.IsClosed())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reg.convert(t))
+}
+
+func (reg *registry) remove(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if reg.get(id) == nil {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+
+	reg.delete(id)
+	reg.save()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (reg *registry) start(w http.ResponseWriter, r *http.Request) {
+	old := reg.get(r.PathValue("id"))
+	if old == nil {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	if !old.IsClosed() {
+		writeJSON(w, http.StatusOK, reg.convert(old))
+		return
+	}
+
+	opts := old.Options()
+	opts.Stats = old.Stats()
+	t, err := reg.restart(old, opts, true)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reg.convert(t))
+}
+
+func (reg *registry) stop(w http.ResponseWriter, r *http.Request) {
+	t := reg.get(r.PathValue("id"))
+	if t == nil {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+
+	t.Close()
+	reg.save()
+
+	writeJSON(w, http.StatusOK, reg.convert(t))
+}
+
+// restart replaces the old tunnel with a new one created from opts, the new tunnel is run if run is true.
+func (reg *registry) restart(old tunnel.Tunnel, opts tunnel.Options, run bool) (tunnel.Tunnel, error) {
+	defer reg.save()
+
+	old.Close()
+
+	t := reg.build(old.Type(), opts)
+	if t == nil {
+		return nil, fmt.Errorf("unknown type %s", old.Type())
+	}
+	reg.set(t)
+
+	if !run {
+		t.Close()
+		return t, nil
+	}
+
+	if err := t.Run(); err != nil {
+		t.Close()
+		return t, err
+	}
+	return t, nil
+}
+
+func (reg *registry) convert(t tunnel.Tunnel) Tunnel {
+	opts := t.Options()
+	v := Tunnel{
+		ID:         t.ID(),
+		Name:       t.Name(),
+		Type:       t.Type(),
+		Endpoint:   t.Endpoint(),
+		Strategy:   opts.Strategy,
+		Routes:     opts.Routes,
+		ReqHeaders: opts.RequestHeaders,
+		ResHeaders: opts.ResponseHeaders,
+		Entrypoint: t.Entrypoint(),
+		Hostname:   opts.Hostname,
+		Username:   opts.Username,
+		EnableTLS:  opts.EnableTLS,
+		TLS:        opts.TLS,
+		Keepalive:  opts.Keepalive,
+		TTL:        opts.TTL,
+		Transport:  tunnel.TransportOf(opts.Transport),
+		Proxies:    proxies(opts.Proxies),
+		Config:     opts.Config,
+		Favorite:   t.IsFavorite(),
+		Closed:     t.IsClosed(),
+		CreatedAt:  opts.CreatedAt,
+		Stats:      t.Stats(),
+	}
+	if err := t.Err(); err != nil {
+		v.Err = err.Error()
+	}
+	if be, ok := t.(tunnel.BackendErrorer); ok && be.BackendErr() != nil {
+		v.BackendErr = be.BackendErr().Error()
+	}
+	if mb, ok := t.(tunnel.MultiBackend); ok {
+		for _, b := range mb.Backends() {
+			v.Backends = append(v.Backends, Backend(b))
+		}
+	}
+	if state, ok := reg.retry(t.ID()); ok {
+		v.Retry = &Retry{
+			Attempts: state.Attempts,
+		}
+		if state.Retrying() {
+			v.Retry.Next = &state.Next
+		}
+		if state.Err != nil {
+			v.Retry.Err = state.Err.Error()
+		}
+	}
+	if reg.health != nil {
+		if health, ok := reg.health(t.ID()); ok {
+			v.Health = &Health{
+				State:     string(health.State),
+				Latency:   health.Latency.Milliseconds(),
+				CheckedAt: health.CheckedAt,
+				Since:     health.Since,
+			}
+			if health.ServerErr != nil {
+				v.Health.ServerErr = health.ServerErr.Error()
+			}
+			if health.EndpointErr != nil {
+				v.Health.EndpointErr = health.EndpointErr.Error()
+			}
+		}
+	}
+	return v
+}
+
+// proxies returns the upstream proxies in use without the passwords.
+func proxies(list []config.Proxy) []config.Proxy {
+	var v []config.Proxy
+	for _, p := range tunnel.ProxiesOf(list) {
+		p.Password = ""
+		v = append(v, p)
+	}
+	return v
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	backupDir = "backups"
+	// MaxBackups is the number of the rotating backups of the config file.
+	MaxBackups = 5
+	// BackupInterval is the minimum interval between two backups while the config is being written.
+	BackupInterval = time.Hour
+
+	backupPrefix     = "config-"
+	backupTimeLayout = "20060102T150405"
+)
+
+var (
+	ErrEmptyConfig = errors.New("config file is empty")
+)
+
+var (
+	lastBackup time.Time
+	backupMu   sync.Mutex
+)
+
+// Recovery describes how a corrupted config file is handled on startup.
+type Recovery struct {
+	// Err is the decoding error of the config file.
+	Err error
+	// Corrupted is the path the corrupted config file is moved to.
+	Corrupted string
+	// Backup is the path of the backup the config is recovered from, it is empty if no backup is usable.
+	Backup string
+}
+
+var (
+	recovery *Recovery
+)
+
+// GetRecovery returns the recovery of the config file on startup, nil if the config file was loaded successfully.
+func GetRecovery() *Recovery {
+	return recovery
+}
+
+// loadFile decodes the config file.
+func loadFile(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(data)
+}
+
+// decodeConfig decodes the config data, an empty content is treated as corrupted,
+// the config file is never written empty, so it is most likely truncated by a crash.
+func decodeConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyConfig
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// recoverConfig moves the corrupted config file aside and loads the latest decodable backup.
+func recoverConfig(err error) (*Config, *Recovery) {
+	r := &Recovery{
+		Err: err,
+	}
+
+	filename := filepath.Join(configDir, configFile)
+	corrupted := fmt.Sprintf("%s.corrupted-%s", filename, time.Now().Format(backupTimeLayout))
+	if err := os.Rename(filename, corrupted); err != nil {
+		slog.Error(fmt.Sprintf("move corrupted config: %v", err))
+	} else {
+		r.Corrupted = corrupted
+	}
+
+	for _, backup := range backups() {
+		cfg, err := loadFile(backup)
+		if err != nil {
+			slog.Error(fmt.Sprintf("load backup %s: %v", backup, err))
+			continue
+		}
+		r.Backup = backup
+		return cfg, r
+	}
+
+	return &Config{}, r
+}
+
+// backups returns the backup files, the latest first.
+func backups() []string {
+	files, _ := filepath.Glob(filepath.Join(configDir, backupDir, backupPrefix+"*.yml"))
+	// the file names contain the backup time, so they are sorted in time order.
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files
+}
+
+// backup saves the config data as a new backup if the last backup is older than BackupInterval or force is true,
+// the data same as the latest backup is skipped, the oldest backups exceeding MaxBackups are removed.
+func backup(data []byte, force bool) error {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+
+	now := time.Now()
+	if !force && now.Sub(lastBackup) < BackupInterval {
+		return nil
+	}
+	if files := backups(); len(files) > 0 {
+		if b, err := os.ReadFile(files[0]); err == nil && bytes.Equal(b, data) {
+			return nil
+		}
+	}
+
+	dir := filepath.Join(configDir, backupDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(dir, backupPrefix+now.Format(backupTimeLayout)+".yml")
+	if err := writeFileAtomic(filename, data, 0600); err != nil {
+		return err
+	}
+	lastBackup = now
+
+	files := backups()
+	for i := MaxBackups; i < len(files); i++ {
+		os.Remove(files[i])
+	}
+	return nil
+}
+
+// WriteFile writes data to the file name in the config directory atomically.
+func WriteFile(name string, data []byte) error {
+	return writeFileAtomic(filepath.Join(configDir, name), data, 0600)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and renames it to filename,
+// so that filename always holds either the old or the new content.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	dir, name := filepath.Split(filename)
+	f, err := os.CreateTemp(dir, "."+strings.TrimSuffix(name, filepath.Ext(name))+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filename)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		err   bool
+		empty bool
+	}{
+		{name: "valid", data: "version: 3\ntunnels:\n- id: a\n  type: tcp\n"},
+		{name: "empty", data: "", err: true, empty: true},
+		{name: "blank", data: "\n  \n", err: true, empty: true},
+		{name: "invalid", data: "tunnels: [", err: true},
+		{name: "wrong type", data: "tunnels: 1\n", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := decodeConfig([]byte(tt.data))
+			if (err != nil) != tt.err {
+				t.Fatalf("got %v, %v", cfg, err)
+			}
+			if errors.Is(err, ErrEmptyConfig) != tt.empty {
+				t.Errorf("got error %v, want empty %v", err, tt.empty)
+			}
+			if err == nil && cfg == nil {
+				t.Error("no config")
+			}
+		})
+	}
+}
+
+func TestRecoverConfig(t *testing.T) {
+	configDir = t.TempDir()
+	lastBackup = time.Time{}
+
+	filename := filepath.Join(configDir, configFile)
+	if err := os.WriteFile(filename, []byte("tunnels: ["), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// no backup, an empty config is used.
+	cfg, r := recoverConfig(ErrEmptyConfig)
+	if cfg == nil || len(cfg.Tunnels) != 0 || r.Backup != "" || r.Corrupted == "" {
+		t.Fatalf("got %+v, %+v", cfg, r)
+	}
+	if b, err := os.ReadFile(r.Corrupted); err != nil || string(b) != "tunnels: [" {
+		t.Errorf("corrupted file is not kept: %v", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("corrupted config is not moved")
+	}
+
+	good := []byte("version: 3\ntunnels:\n- id: a\n  type: tcp\n")
+	if err := backup(good, true); err != nil {
+		t.Fatal(err)
+	}
+	// the same content is not backed up again.
+	if err := backup(good, true); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(backups()); n != 1 {
+		t.Fatalf("got %d backups, want 1", n)
+	}
+	// a newer broken backup is skipped.
+	if err := os.WriteFile(filepath.Join(configDir, backupDir, backupPrefix+"99991231T235959.yml"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, r = recoverConfig(ErrEmptyConfig)
+	if len(cfg.Tunnels) != 1 || cfg.Tunnels[0].ID != "a" {
+		t.Errorf("got %+v, want the config of the backup", cfg)
+	}
+	if r.Backup != backups()[1] {
+		t.Errorf("recovered from %s, want %s", r.Backup, backups()[1])
+	}
+	if r.Corrupted != "" {
+		t.Errorf("got corrupted %s, there is no config file to move", r.Corrupted)
+	}
+}
+
+func TestBackupRotation(t *testing.T) {
+	configDir = t.TempDir()
+	lastBackup = time.Time{}
+
+	if err := backup([]byte("version: 0\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	// the backups within BackupInterval are skipped unless forced.
+	if err := backup([]byte("version: 1\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(backups()); n != 1 {
+		t.Fatalf("got %d backups, want 1", n)
+	}
+
+	dir := filepath.Join(configDir, backupDir)
+	for i := 0; i < MaxBackups+2; i++ {
+		name := backupPrefix + time.Date(2000, 1, 1, 0, 0, i, 0, time.UTC).Format(backupTimeLayout) + ".yml"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("version: 2\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := backup([]byte("version: 3\n"), true); err != nil {
+		t.Fatal(err)
+	}
+
+	files := backups()
+	if len(files) != MaxBackups {
+		t.Fatalf("got %d backups, want %d", len(files), MaxBackups)
+	}
+	if b, _ := os.ReadFile(files[0]); string(b) != "version: 3\n" {
+		t.Errorf("latest backup is %q", b)
+	}
+}
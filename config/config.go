@@ -2,10 +2,13 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -29,8 +32,15 @@ func init() {
 	config.Store(&Config{})
 }
 
-func Init() {
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: true})))
+// Dir returns the directory of the config file.
+func Dir() string {
+	return configDir
+}
+
+// Init loads the config file, a config which can not be decoded is recovered from the backups.
+// The other errors reading the config file are returned as is, the file is left untouched then.
+func Init() error {
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{AddSource: true})))
 
 	dir, err := app.DataDir()
 	if err != nil {
@@ -44,16 +54,70 @@ func Init() {
 
 	slog.Info(fmt.Sprintf("appDir: %s", configDir))
 
-	cfg := Get()
-	if err := cfg.load(); err != nil {
+	filename := filepath.Join(configDir, configFile)
+	var cfg *Config
+	data, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err == nil {
+		cfg, err = decodeConfig(data)
+	}
+	write := false
+	switch {
+	case err == nil:
+		// keep a copy of the config known to be good before it is changed.
+		if err := backup(data, true); err != nil {
+			slog.Error(fmt.Sprintf("backup config: %v", err))
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		cfg = &Config{Version: Version}
+		write = true
+	default:
+		// only the decoding errors and an empty file are left here.
 		slog.Error(fmt.Sprintf("load config: %v", err))
-		if _, ok := err.(*os.PathError); ok {
-			cfg.Write()
+		cfg, recovery = recoverConfig(err)
+		slog.Warn(fmt.Sprintf("config recovered from backup %q, the corrupted file is moved to %s", recovery.Backup, recovery.Corrupted))
+		write = true
+	}
+
+	if err := loadStats(); err != nil {
+		slog.Error(fmt.Sprintf("load stats: %v", err))
+	}
+
+	if changed, err := cfg.migrate(); err != nil {
+		slog.Error(err.Error())
+	} else if changed {
+		write = true
+	}
+
+	secret := cfg.Secret
+	keyErr = cfg.initSecretKey()
+	// migrate the plain-text passwords and the file permission.
+	plain := cfg.decryptSecrets()
+	if keyErr != nil {
+		slog.Error(fmt.Sprintf("secret key: %v", keyErr))
+		if cfg.encryptedSecret() != "" {
+			secretErr = keyErr
+		} else if plain {
+			secretErr = fmt.Errorf("%w: %w", ErrNoSecretKey, keyErr)
+		}
+	}
+	if plain || secret != cfg.Secret {
+		write = true
+	}
+
+	if write {
+		if err := cfg.Write(); err != nil {
+			slog.Error(fmt.Sprintf("write config: %v", err))
 		}
+	} else if data, err := encodeConfig(cfg); err == nil {
+		lastWrite = data
 	}
 	Set(cfg)
 
 	initLog()
+	return nil
 }
 
 func initLog() {
@@ -106,6 +170,58 @@ type Settings struct {
 	Entrypoint string
 	Lang       string
 	Theme      string
+	// Transport to the tunnel server, default is wss.
+	Transport *Transport `yaml:",omitempty"`
+	// Proxies are the upstream proxies the tunnel server is reached through, in order.
+	Proxies []Proxy `yaml:",omitempty"`
+	// SystemProxy uses the proxy in the HTTPS_PROXY and NO_PROXY environment variables if there are no Proxies.
+	SystemProxy bool `yaml:"systemProxy,omitempty"`
+	// ServerTLS verifies the tunnel server and authenticates the client to it.
+	ServerTLS *ServerTLS `yaml:"serverTLS,omitempty"`
+}
+
+// Transport is the gost dialer used to reach the tunnel server.
+type Transport struct {
+	// Type of the dialer, see tunnel.Transports.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Path of the ws, wss, h2 and grpc requests, the default of the dialer is used if it is empty.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Metadata are the other options of the dialer, e.g. keepalive or kcp.mode.
+	Metadata map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+// TLS is the client side TLS of the HTTP tunnel to the endpoints.
+type TLS struct {
+	// CA is the PEM file of the certificates verifying the endpoints, the system roots are used if it is empty.
+	CA string `yaml:"ca,omitempty" json:"ca,omitempty"`
+	// ServerName is verified against the endpoint certificate, default is the host of the endpoint.
+	ServerName string `yaml:"serverName,omitempty" json:"serverName,omitempty"`
+	// Cert and Key are the PEM files of the client certificate.
+	Cert string `yaml:"cert,omitempty" json:"cert,omitempty"`
+	Key  string `yaml:"key,omitempty" json:"key,omitempty"`
+	// Insecure skips the verification of the endpoint certificate.
+	Insecure bool `yaml:"insecure,omitempty" json:"insecure,omitempty"`
+}
+
+// ServerTLS is the TLS to the tunnel server, it is used by the transports over TLS.
+type ServerTLS struct {
+	// CA is the PEM file of the certificates verifying the tunnel server, the system roots are used if it is empty.
+	CA string `yaml:"ca,omitempty"`
+	// Cert and Key are the PEM files of the client certificate.
+	Cert string `yaml:"cert,omitempty"`
+	Key  string `yaml:"key,omitempty"`
+	// Pins are the SHA-256 fingerprints of the trusted server certificates in hex, the server is trusted
+	// only if its leaf certificate is pinned. With a CA the certificate must also be verified by the CA.
+	Pins []string `yaml:"pins,omitempty"`
+}
+
+// Proxy is an upstream HTTP or SOCKS5 proxy on the way to the tunnel server.
+type Proxy struct {
+	// Type of the proxy, http, https or socks5.
+	Type     string `yaml:"type" json:"type"`
+	Addr     string `yaml:"addr" json:"addr"`
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
 }
 
 type Tunnel struct {
@@ -117,43 +233,166 @@ type Tunnel struct {
 	Username  string `yaml:",omitempty"`
 	Password  string `yaml:",omitempty"`
 	EnableTLS bool   `yaml:"enableTLS,omitempty"`
-	Keepalive bool   `yaml:",omitempty"`
-	TTL       int    `yaml:"ttl,omitempty"`
+	// TLS is used by the HTTP tunnel to connect to the endpoints if EnableTLS is true.
+	TLS       *TLS `yaml:"tls,omitempty"`
+	Keepalive bool `yaml:",omitempty"`
+	TTL       int  `yaml:"ttl,omitempty"`
+	// Strategy selects one of the comma-separated endpoints, see tunnel.StrategyRoundRobin.
+	Strategy string `yaml:",omitempty"`
+	// Routes forward the matched requests of the HTTP tunnel to other endpoints.
+	Routes []HTTPRoute `yaml:",omitempty"`
+	// RequestHeaders and ResponseHeaders are the header rules of the HTTP tunnel.
+	RequestHeaders  []HTTPHeader `yaml:"requestHeaders,omitempty"`
+	ResponseHeaders []HTTPHeader `yaml:"responseHeaders,omitempty"`
+	// Transport overrides the transport in the settings.
+	Transport *Transport `yaml:",omitempty"`
+	// Proxies override the upstream proxies in the settings.
+	Proxies []Proxy `yaml:",omitempty"`
+	// Config is the raw gost config of the advanced tunnel.
+	Config string `yaml:",omitempty"`
 
-	Stats     ServiceStats
+	// Stats is only decoded from the config files before version 2, see StatsStore.
+	Stats     ServiceStats `yaml:",omitempty"`
 	Favorite  bool
 	Closed    bool
 	CreatedAt time.Time
 }
 
+// HTTPRoute forwards the requests matching all the non-empty conditions to the endpoint.
+type HTTPRoute struct {
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+	// Path is the path prefix of the requests.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Header is the name of a request header, optionally followed by the value, e.g. "X-Env: staging".
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+	// StripPrefix removes the path prefix before the request is forwarded.
+	StripPrefix bool   `yaml:"stripPrefix,omitempty" json:"stripPrefix,omitempty"`
+	Endpoint    string `yaml:"endpoint" json:"endpoint"`
+}
+
+// HTTPHeader sets or removes a header of the HTTP requests or responses.
+type HTTPHeader struct {
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+	// Remove deletes the header, the value is ignored.
+	Remove bool `yaml:"remove,omitempty" json:"remove,omitempty"`
+}
+
+type APIConfig struct {
+	// Listen address of the control API, the API is disabled if it is empty.
+	Addr string
+	// Bearer token required by the API, it is generated automatically if it is empty.
+	Token string `yaml:",omitempty"`
+}
+
+type MetricsConfig struct {
+	// Listen address of the Prometheus metrics exporter, the exporter is disabled if it is empty.
+	Addr string
+	// Path of the metrics endpoint, default is /metrics.
+	Path string `yaml:",omitempty"`
+}
+
+// ServerConfig is the built-in tunnel server, which the tunnels and entrypoints of the clients connect to.
+type ServerConfig struct {
+	// Listen address of the tunnel server, the server is disabled if it is empty.
+	Addr string
+	// Transport of the tunnel server, the clients must use the same transport, default is wss.
+	Transport *Transport `yaml:",omitempty"`
+	// Cert and Key are the PEM files of the server certificate, a self-signed certificate is generated if they are empty.
+	Cert string `yaml:",omitempty"`
+	Key  string `yaml:",omitempty"`
+	// ClientCA is the PEM file of the CA verifying the client certificates, the clients without a certificate signed by it are rejected.
+	ClientCA string `yaml:"clientCA,omitempty"`
+	// Entrypoint is the listen address of the public traffic. The HTTP requests are routed to the tunnels by the Host header,
+	// and the TLS connections by the SNI, e.g. <endpoint>.example.com is routed to the tunnel of the endpoint.
+	Entrypoint string `yaml:",omitempty"`
+	// Ingress maps the hostnames to the tunnels, in addition to the hostnames requested by the tunnels.
+	Ingress []IngressRule `yaml:",omitempty"`
+}
+
+// IngressRule routes the public traffic of the hostname to the tunnel.
+type IngressRule struct {
+	Hostname string `yaml:"hostname" json:"hostname"`
+	// Tunnel is the tunnel ID.
+	Tunnel string `yaml:"tunnel" json:"tunnel"`
+}
+
 type Config struct {
+	// Schema version of the config file, see Version.
+	Version     int
 	Settings    *Settings
 	Tunnels     []*Tunnel
 	EntryPoints []*Tunnel
+	API         *APIConfig     `yaml:"api,omitempty"`
+	Metrics     *MetricsConfig `yaml:",omitempty"`
+	Server      *ServerConfig  `yaml:",omitempty"`
+	Secret      *SecretConfig  `yaml:",omitempty"`
 	Log         *xconfig.LogConfig
 }
 
-func (c *Config) load() error {
-	f, err := os.Open(filepath.Join(configDir, configFile))
+var (
+	writeMu sync.Mutex
+	// lastWrite is the content of the latest write with the passwords in plain text, an unchanged config is not written again.
+	lastWrite []byte
+)
+
+// Write saves the config atomically if it is changed, a backup is made every BackupInterval.
+// The config is not written if its passwords can not be decrypted or encrypted, see SecretError.
+func (c *Config) Write() error {
+	if secretErr != nil {
+		return fmt.Errorf("config is read-only: %w", secretErr)
+	}
+
+	// the passwords are encrypted with a fresh nonce on every write, the plain config tells whether it is changed.
+	plain, err := encodeConfig(c)
+	if err != nil {
+		return err
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	if bytes.Equal(plain, lastWrite) {
+		return nil
+	}
+
+	v := *c
+	if c.Settings != nil && len(c.Settings.Proxies) > 0 {
+		settings := *c.Settings
+		if settings.Proxies, err = encryptProxies(c.Settings.Proxies); err != nil {
+			return err
+		}
+		v.Settings = &settings
+	}
+	if v.Tunnels, err = encryptTunnels(c.Tunnels); err != nil {
+		return err
+	}
+	if v.EntryPoints, err = encryptTunnels(c.EntryPoints); err != nil {
+		return err
+	}
+
+	data, err := encodeConfig(&v)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	if err := writeFileAtomic(filepath.Join(configDir, configFile), data, 0600); err != nil {
+		return err
+	}
+	lastWrite = plain
 
-	return yaml.NewDecoder(f).Decode(c)
+	return backup(data, false)
 }
 
-func (c *Config) Write() error {
+func encodeConfig(c *Config) ([]byte, error) {
 	var buf bytes.Buffer
 	enc := yaml.NewEncoder(&buf)
 	defer enc.Close()
 
 	enc.SetIndent(2)
 	if err := enc.Encode(c); err != nil {
-		return err
+		return nil, err
 	}
-
-	return os.WriteFile(filepath.Join(configDir, configFile), buf.Bytes(), 0644)
+	return buf.Bytes(), nil
 }
 
 type ServiceStats struct {
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	lockFile    = "gost-plus.lock"
+	lockTimeout = 5 * time.Second
+)
+
+var (
+	ErrRunning = errors.New("gost.plus is running")
+)
+
+// Lock marks the client as running by creating the lock file with its PID in the config directory.
+// It returns ErrRunning if the lock is held by a living process, a lock file left by a crashed client is taken over.
+func Lock() error {
+	filename := filepath.Join(configDir, lockFile)
+	for i := 0; ; i++ {
+		f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(filename)
+			}
+			return err
+		}
+		if !errors.Is(err, fs.ErrExist) || i > 0 {
+			return err
+		}
+
+		pid := lockPID()
+		if pid == os.Getpid() {
+			return nil
+		}
+		if pid > 0 && processAlive(pid) {
+			return fmt.Errorf("%w (PID %d)", ErrRunning, pid)
+		}
+		// the PID is not written yet by the process just created the lock file.
+		if pid <= 0 {
+			if fi, err := os.Stat(filename); err == nil && time.Since(fi.ModTime()) < lockTimeout {
+				return ErrRunning
+			}
+		}
+		if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+}
+
+// Unlock removes the lock file written by Lock.
+func Unlock() {
+	if pid := lockPID(); pid == os.Getpid() {
+		os.Remove(filepath.Join(configDir, lockFile))
+	}
+}
+
+func lockPID() int {
+	b, err := os.ReadFile(filepath.Join(configDir, lockFile))
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(b)))
+	return pid
+}
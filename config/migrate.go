@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Version is the current schema version of the config file.
+const Version = 3
+
+type migration struct {
+	// version is the schema version after the migration.
+	version int
+	migrate func(c *Config) error
+}
+
+// migrations upgrade the config from the previous schema version, they must be kept in version order.
+var migrations = []migration{
+	{
+		// the config without version may have tunnels without creation time,
+		// which is then reset on every start.
+		version: 1,
+		migrate: func(c *Config) error {
+			now := time.Now()
+			for _, tunnels := range [][]*Tunnel{c.Tunnels, c.EntryPoints} {
+				for _, t := range tunnels {
+					if t != nil && t.CreatedAt.IsZero() {
+						t.CreatedAt = now
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		// the stats are moved to the stats file, so that the config file only changes on user edits.
+		version: 2,
+		migrate: func(c *Config) error {
+			statsMu.Lock()
+			for _, v := range []struct {
+				tunnels []*Tunnel
+				stats   *map[string]ServiceStats
+			}{
+				{c.Tunnels, &stats.Tunnels},
+				{c.EntryPoints, &stats.EntryPoints},
+			} {
+				for _, t := range v.tunnels {
+					if t == nil || t.Stats == (ServiceStats{}) {
+						continue
+					}
+					if *v.stats == nil {
+						*v.stats = make(map[string]ServiceStats)
+					}
+					if _, ok := (*v.stats)[t.ID]; !ok {
+						(*v.stats)[t.ID] = t.Stats
+					}
+					t.Stats = ServiceStats{}
+				}
+			}
+			statsMu.Unlock()
+
+			return SaveStats()
+		},
+	},
+	{
+		// the endpoint certificates of the HTTP tunnels were not verified before the TLS settings,
+		// the existing tunnels keep working with the self-signed certificates.
+		version: 3,
+		migrate: func(c *Config) error {
+			for _, t := range c.Tunnels {
+				if t != nil && t.EnableTLS && t.TLS == nil {
+					t.TLS = &TLS{Insecure: true}
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// migrate upgrades the config to the current schema version, it reports whether the config is changed.
+func (c *Config) migrate() (bool, error) {
+	if c.Version > Version {
+		return false, fmt.Errorf("config version %d is newer than the supported version %d", c.Version, Version)
+	}
+
+	from := c.Version
+	for _, m := range migrations {
+		if m.version <= c.Version {
+			continue
+		}
+		if err := m.migrate(c); err != nil {
+			return false, fmt.Errorf("migrate config to version %d: %w", m.version, err)
+		}
+		c.Version = m.version
+	}
+	return c.Version != from, nil
+}
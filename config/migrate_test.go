@@ -0,0 +1,104 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	configDir = t.TempDir()
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		changed bool
+		err     bool
+		check   func(t *testing.T, c *Config)
+	}{
+		{
+			name:    "current",
+			cfg:     &Config{Version: Version},
+			changed: false,
+		},
+		{
+			name:    "newer",
+			cfg:     &Config{Version: Version + 1},
+			changed: false,
+			err:     true,
+		},
+		{
+			name: "creation time",
+			cfg: &Config{
+				Tunnels:     []*Tunnel{{ID: "a"}, nil},
+				EntryPoints: []*Tunnel{{ID: "b"}},
+			},
+			changed: true,
+			check: func(t *testing.T, c *Config) {
+				if c.Tunnels[0].CreatedAt.IsZero() || c.EntryPoints[0].CreatedAt.IsZero() {
+					t.Error("creation time is not set")
+				}
+			},
+		},
+		{
+			name: "stats",
+			cfg: &Config{
+				Version:     1,
+				Tunnels:     []*Tunnel{{ID: "a", Stats: ServiceStats{TotalConns: 3}}},
+				EntryPoints: []*Tunnel{{ID: "b", Stats: ServiceStats{TotalErrs: 2}}},
+			},
+			changed: true,
+			check: func(t *testing.T, c *Config) {
+				if c.Tunnels[0].Stats != (ServiceStats{}) || c.EntryPoints[0].Stats != (ServiceStats{}) {
+					t.Error("stats are kept in the config")
+				}
+				if TunnelStats("a").TotalConns != 3 || EntryPointStats("b").TotalErrs != 2 {
+					t.Errorf("got stats %+v %+v", TunnelStats("a"), EntryPointStats("b"))
+				}
+			},
+		},
+		{
+			name: "insecure TLS",
+			cfg: &Config{
+				Version: 2,
+				Tunnels: []*Tunnel{
+					{ID: "a", EnableTLS: true},
+					{ID: "b", EnableTLS: true, TLS: &TLS{ServerName: "example.com"}},
+					{ID: "c"},
+				},
+			},
+			changed: true,
+			check: func(t *testing.T, c *Config) {
+				if tls := c.Tunnels[0].TLS; tls == nil || !tls.Insecure {
+					t.Errorf("got TLS %+v, want insecure", tls)
+				}
+				if tls := c.Tunnels[1].TLS; tls.Insecure || tls.ServerName != "example.com" {
+					t.Errorf("got TLS %+v, want unchanged", tls)
+				}
+				if c.Tunnels[2].TLS != nil {
+					t.Errorf("TLS is set without EnableTLS")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version := tt.cfg.Version
+			changed, err := tt.cfg.migrate()
+			if (err != nil) != tt.err {
+				t.Fatalf("got error %v, want error %v", err, tt.err)
+			}
+			if changed != tt.changed {
+				t.Errorf("got changed %v, want %v", changed, tt.changed)
+			}
+			if !tt.err && tt.cfg.Version != Version {
+				t.Errorf("got version %d, want %d", tt.cfg.Version, Version)
+			}
+			if tt.err && tt.cfg.Version != version {
+				t.Errorf("version is changed to %d", tt.cfg.Version)
+			}
+			if tt.check != nil {
+				tt.check(t, tt.cfg)
+			}
+		})
+	}
+}
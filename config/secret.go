@@ -0,0 +1,376 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// PassphraseEnv is the environment variable of the master passphrase.
+	PassphraseEnv = "GOST_PLUS_PASSPHRASE"
+
+	KeySourceKeyring    = "keyring"
+	KeySourcePassphrase = "passphrase"
+
+	secretPrefix   = "enc:v1:"
+	secretCheck    = "gost.plus"
+	keyringService = "gost.plus"
+	keyringUser    = "config"
+)
+
+var (
+	ErrNoPassphrase = errors.New("master passphrase is required to decrypt the config")
+	ErrNoSecretKey  = errors.New("no encryption key")
+	ErrKeyMismatch  = errors.New("the encryption key does not match the one the passwords are encrypted with")
+)
+
+type SecretConfig struct {
+	// Source of the key encrypting the passwords, keyring or passphrase.
+	KeySource string `yaml:"keySource"`
+	// Salt for deriving the key from the master passphrase.
+	Salt string `yaml:",omitempty"`
+	// Check is a known text encrypted with the key, it detects a wrong passphrase or a replaced keyring key.
+	Check string `yaml:",omitempty"`
+}
+
+var (
+	passphrase string
+	secretKey  []byte
+	// secretErr is the error of the encryption key if the config has passwords which can not be decrypted or encrypted,
+	// the config is not written then, otherwise the passwords would be lost or stored in plain text.
+	secretErr error
+	// keyErr is the error of the encryption key, the passwords added later can not be encrypted either.
+	keyErr     error
+	secretErrs = make(chan error, 1)
+)
+
+// SetPassphrase sets the master passphrase used instead of the OS keyring, it must be called before Init.
+func SetPassphrase(s string) {
+	passphrase = s
+}
+
+// SecretError returns the error of the encryption key if the passwords in the config can not be decrypted,
+// the config is read-only then.
+func SecretError() error {
+	return secretErr
+}
+
+// SecretErrors returns the errors of the writes refused because the passwords can not be encrypted.
+func SecretErrors() <-chan error {
+	return secretErrs
+}
+
+// noSecretKey returns the error of a password which can not be encrypted, the user is notified by SecretErrors.
+func noSecretKey() error {
+	err := ErrNoSecretKey
+	if keyErr != nil {
+		err = fmt.Errorf("%w: %w", ErrNoSecretKey, keyErr)
+	}
+	select {
+	case secretErrs <- err:
+	default:
+	}
+	return err
+}
+
+// initSecretKey resolves the encryption key from the source recorded in the config and verifies it with the check value.
+// A new config uses the passphrase if it is set, otherwise the OS keyring.
+func (c *Config) initSecretKey() error {
+	var source string
+	if c.Secret != nil {
+		source = c.Secret.KeySource
+	}
+	if source == "" {
+		source = KeySourceKeyring
+		if passphrase != "" {
+			source = KeySourcePassphrase
+		}
+	}
+
+	switch source {
+	case KeySourcePassphrase:
+		if passphrase == "" {
+			return ErrNoPassphrase
+		}
+
+		var salt []byte
+		if c.Secret != nil && c.Secret.Salt != "" {
+			v, err := base64.StdEncoding.DecodeString(c.Secret.Salt)
+			if err != nil {
+				return err
+			}
+			salt = v
+		} else {
+			salt = make([]byte, 16)
+			if _, err := rand.Read(salt); err != nil {
+				return err
+			}
+		}
+		secretKey = argon2.IDKey([]byte(passphrase), salt, 1, 64*1024, 4, 32)
+		if c.Secret == nil || c.Secret.Salt == "" {
+			c.Secret = &SecretConfig{
+				KeySource: source,
+				Salt:      base64.StdEncoding.EncodeToString(salt),
+			}
+		}
+
+	case KeySourceKeyring:
+		// a new key is only generated if there is nothing encrypted with the lost one.
+		key, err := keyringKey(c.encryptedSecret() == "")
+		if err != nil {
+			return fmt.Errorf("keyring: %w", err)
+		}
+		secretKey = key
+		if c.Secret == nil {
+			c.Secret = &SecretConfig{
+				KeySource: source,
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown key source %s", source)
+	}
+
+	if err := c.checkSecretKey(); err != nil {
+		secretKey = nil
+		return err
+	}
+	return nil
+}
+
+// checkSecretKey verifies the key with the check value, or with an encrypted password if there is no check value yet.
+// The check value is renewed if there is nothing encrypted with the old key.
+func (c *Config) checkSecretKey() error {
+	encrypted := c.encryptedSecret()
+
+	var ok bool
+	if c.Secret.Check != "" {
+		if v, err := decryptSecret(c.Secret.Check); err == nil && v == secretCheck {
+			return nil
+		}
+	} else if encrypted != "" {
+		_, err := decryptSecret(encrypted)
+		ok = err == nil
+	}
+	if !ok && encrypted != "" {
+		return ErrKeyMismatch
+	}
+
+	v, err := encryptSecret(secretCheck)
+	if err != nil {
+		return err
+	}
+	secret := *c.Secret
+	secret.Check = v
+	c.Secret = &secret
+	return nil
+}
+
+// encryptedSecret returns the first encrypted password in the config, empty if there is none.
+func (c *Config) encryptedSecret() string {
+	var proxies [][]Proxy
+	if c.Settings != nil {
+		proxies = append(proxies, c.Settings.Proxies)
+	}
+	for _, tunnels := range [][]*Tunnel{c.Tunnels, c.EntryPoints} {
+		for _, t := range tunnels {
+			if t == nil {
+				continue
+			}
+			if strings.HasPrefix(t.Password, secretPrefix) {
+				return t.Password
+			}
+			proxies = append(proxies, t.Proxies)
+		}
+	}
+	for _, list := range proxies {
+		for _, p := range list {
+			if strings.HasPrefix(p.Password, secretPrefix) {
+				return p.Password
+			}
+		}
+	}
+	return ""
+}
+
+// keyringKey returns the key stored in the OS keyring, a new key is generated if it does not exist and create is true.
+func keyringKey(create bool) ([]byte, error) {
+	v, err := keyring.Get(keyringService, keyringUser)
+	if err == nil {
+		return base64.StdEncoding.DecodeString(v)
+	}
+	if !errors.Is(err, keyring.ErrNotFound) || !create {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := keyring.Set(keyringService, keyringUser, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func encryptSecret(s string) (string, error) {
+	if s == "" || strings.HasPrefix(s, secretPrefix) {
+		return s, nil
+	}
+	if secretKey == nil {
+		return "", noSecretKey()
+	}
+
+	gcm, err := newGCM(secretKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	b := gcm.Seal(nonce, nonce, []byte(s), nil)
+	return secretPrefix + base64.StdEncoding.EncodeToString(b), nil
+}
+
+func decryptSecret(s string) (string, error) {
+	v, ok := strings.CutPrefix(s, secretPrefix)
+	if !ok {
+		return s, nil
+	}
+	if secretKey == nil {
+		return "", ErrNoSecretKey
+	}
+
+	b, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(secretKey)
+	if err != nil {
+		return "", err
+	}
+	if len(b) < gcm.NonceSize() {
+		return "", errors.New("invalid secret")
+	}
+	plain, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptSecrets decrypts the passwords in place, plain reports whether there are plain-text passwords to be migrated.
+// The passwords which can not be decrypted are kept as is, so they are not lost on the next write.
+func (c *Config) decryptSecrets() (plain bool) {
+	if c.Settings != nil && decryptProxies(c.Settings.Proxies) {
+		plain = true
+	}
+	for _, tunnels := range [][]*Tunnel{c.Tunnels, c.EntryPoints} {
+		for _, t := range tunnels {
+			if t == nil {
+				continue
+			}
+			if decryptProxies(t.Proxies) {
+				plain = true
+			}
+			if t.Password == "" {
+				continue
+			}
+			if !strings.HasPrefix(t.Password, secretPrefix) {
+				plain = true
+				continue
+			}
+			v, err := decryptSecret(t.Password)
+			if err != nil {
+				slog.Error(fmt.Sprintf("decrypt password of %s: %v", t.Name, err))
+				continue
+			}
+			t.Password = v
+		}
+	}
+	return
+}
+
+func decryptProxies(proxies []Proxy) (plain bool) {
+	for i := range proxies {
+		p := &proxies[i]
+		if p.Password == "" {
+			continue
+		}
+		if !strings.HasPrefix(p.Password, secretPrefix) {
+			plain = true
+			continue
+		}
+		v, err := decryptSecret(p.Password)
+		if err != nil {
+			slog.Error(fmt.Sprintf("decrypt password of proxy %s: %v", p.Addr, err))
+			continue
+		}
+		p.Password = v
+	}
+	return
+}
+
+// encryptProxies returns a copy of the proxies with the passwords encrypted, see encryptTunnels.
+func encryptProxies(proxies []Proxy) ([]Proxy, error) {
+	if proxies == nil {
+		return nil, nil
+	}
+
+	list := make([]Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		password, err := encryptSecret(p.Password)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt password of proxy %s: %w", p.Addr, err)
+		}
+		p.Password = password
+		list = append(list, p)
+	}
+	return list, nil
+}
+
+// encryptTunnels returns a copy of the tunnels with the passwords encrypted with a fresh nonce,
+// it fails if there is no encryption key, the passwords are never stored in plain text.
+func encryptTunnels(tunnels []*Tunnel) ([]*Tunnel, error) {
+	if tunnels == nil {
+		return nil, nil
+	}
+
+	list := make([]*Tunnel, 0, len(tunnels))
+	for _, t := range tunnels {
+		if t == nil || (t.Password == "" && len(t.Proxies) == 0) {
+			list = append(list, t)
+			continue
+		}
+
+		v := *t
+		proxies, err := encryptProxies(t.Proxies)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Name, err)
+		}
+		v.Proxies = proxies
+		if v.Password, err = encryptSecret(t.Password); err != nil {
+			return nil, fmt.Errorf("encrypt password of %s: %w", t.Name, err)
+		}
+		list = append(list, &v)
+	}
+	return list, nil
+}
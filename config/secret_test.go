@@ -0,0 +1,254 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withKey sets the encryption key for the test, the key state is restored afterwards.
+func withKey(t *testing.T, key []byte) {
+	t.Helper()
+
+	secretKey, secretErr, keyErr, lastWrite = key, nil, nil, nil
+	t.Cleanup(func() {
+		secretKey, secretErr, keyErr, lastWrite = nil, nil, nil, nil
+	})
+}
+
+func testKey(b byte) []byte {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = b
+	}
+	return key
+}
+
+func TestEncryptSecret(t *testing.T) {
+	withKey(t, testKey(1))
+
+	v1, err := encryptSecret("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := encryptSecret("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(v1, secretPrefix) || strings.Contains(v1, "secret") {
+		t.Errorf("got %s", v1)
+	}
+	// the same passwords must not have the same ciphertext.
+	if v1 == v2 {
+		t.Errorf("got the same ciphertext %s for the same password", v1)
+	}
+
+	for _, v := range []string{v1, v2} {
+		if s, err := decryptSecret(v); err != nil || s != "secret" {
+			t.Errorf("decryptSecret(%s) = %s, %v", v, s, err)
+		}
+	}
+
+	// the empty and encrypted ones are kept as is.
+	for _, s := range []string{"", v1} {
+		if v, err := encryptSecret(s); err != nil || v != s {
+			t.Errorf("encryptSecret(%q) = %s, %v", s, v, err)
+		}
+	}
+	if v, err := decryptSecret("plain"); err != nil || v != "plain" {
+		t.Errorf("decryptSecret(plain) = %s, %v", v, err)
+	}
+
+	for _, v := range []string{secretPrefix + "!!!", secretPrefix + "AAAA", v1[:len(v1)-4] + "AAAA"} {
+		if _, err := decryptSecret(v); err == nil {
+			t.Errorf("decryptSecret(%s) succeeds", v)
+		}
+	}
+
+	withKey(t, testKey(2))
+	if _, err := decryptSecret(v1); err == nil {
+		t.Error("decrypted with a wrong key")
+	}
+
+	withKey(t, nil)
+	if _, err := encryptSecret("secret"); !errors.Is(err, ErrNoSecretKey) {
+		t.Errorf("got error %v, want %v", err, ErrNoSecretKey)
+	}
+	select {
+	case err := <-SecretErrors():
+		if !errors.Is(err, ErrNoSecretKey) {
+			t.Errorf("got notified error %v", err)
+		}
+	default:
+		t.Error("the error is not notified")
+	}
+}
+
+func TestCheckSecretKey(t *testing.T) {
+	withKey(t, testKey(1))
+	check, err := encryptSecret(secretCheck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	password, err := encryptSecret("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		key  []byte
+		cfg  *Config
+		err  error
+		// renewed reports whether the check value is replaced.
+		renewed bool
+	}{
+		{
+			name: "check value",
+			key:  testKey(1),
+			cfg:  &Config{Secret: &SecretConfig{Check: check}},
+		},
+		{
+			name: "wrong key",
+			key:  testKey(2),
+			cfg:  &Config{Secret: &SecretConfig{Check: check}, Tunnels: []*Tunnel{{Password: password}}},
+			err:  ErrKeyMismatch,
+		},
+		{
+			name:    "wrong key without passwords",
+			key:     testKey(2),
+			cfg:     &Config{Secret: &SecretConfig{Check: check}},
+			renewed: true,
+		},
+		{
+			name:    "no check value",
+			key:     testKey(1),
+			cfg:     &Config{Secret: &SecretConfig{}, Tunnels: []*Tunnel{{Password: password}}},
+			renewed: true,
+		},
+		{
+			name: "no check value with wrong key",
+			key:  testKey(2),
+			cfg:  &Config{Secret: &SecretConfig{}, EntryPoints: []*Tunnel{{Proxies: []Proxy{{Password: password}}}}},
+			err:  ErrKeyMismatch,
+		},
+		{
+			name:    "new config",
+			key:     testKey(2),
+			cfg:     &Config{Secret: &SecretConfig{}},
+			renewed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withKey(t, tt.key)
+
+			prev := tt.cfg.Secret
+			err := tt.cfg.checkSecretKey()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if renewed := tt.cfg.Secret.Check != prev.Check; renewed != tt.renewed {
+				t.Errorf("got renewed %v, want %v", renewed, tt.renewed)
+			}
+			if tt.err != nil {
+				return
+			}
+			if v, err := decryptSecret(tt.cfg.Secret.Check); err != nil || v != secretCheck {
+				t.Errorf("got check value %s, %v", v, err)
+			}
+		})
+	}
+}
+
+func TestInitSecretKeyPassphrase(t *testing.T) {
+	withKey(t, nil)
+	passphrase = "passphrase"
+	t.Cleanup(func() { passphrase = "" })
+
+	c := &Config{}
+	if err := c.initSecretKey(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Secret.KeySource != KeySourcePassphrase || c.Secret.Salt == "" || c.Secret.Check == "" {
+		t.Fatalf("got secret config %+v", c.Secret)
+	}
+	password, err := encryptSecret("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Tunnels = []*Tunnel{{Password: password}}
+
+	// the same passphrase derives the same key from the salt.
+	withKey(t, nil)
+	if err := c.initSecretKey(); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := decryptSecret(password); err != nil || v != "secret" {
+		t.Errorf("got %s, %v", v, err)
+	}
+
+	withKey(t, nil)
+	passphrase = "wrong"
+	if err := c.initSecretKey(); !errors.Is(err, ErrKeyMismatch) || secretKey != nil {
+		t.Errorf("got error %v, want %v", err, ErrKeyMismatch)
+	}
+
+	passphrase = ""
+	if err := c.initSecretKey(); !errors.Is(err, ErrNoPassphrase) {
+		t.Errorf("got error %v, want %v", err, ErrNoPassphrase)
+	}
+}
+
+func TestWriteSecrets(t *testing.T) {
+	configDir = t.TempDir()
+	filename := filepath.Join(configDir, configFile)
+	withKey(t, testKey(1))
+
+	c := &Config{
+		Version:  Version,
+		Settings: &Settings{Proxies: []Proxy{{Addr: "proxy:3128", Password: "proxy-secret"}}},
+		Tunnels:  []*Tunnel{{ID: "a", Password: "tunnel-secret"}},
+	}
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Errorf("passwords are written in plain text:\n%s", b)
+	}
+	if c.Tunnels[0].Password != "tunnel-secret" || c.Settings.Proxies[0].Password != "proxy-secret" {
+		t.Errorf("the passwords in memory are changed")
+	}
+
+	// an unchanged config is not written again, even though the ciphertext would differ.
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if b2, _ := os.ReadFile(filename); string(b2) != string(b) {
+		t.Errorf("unchanged config is written again")
+	}
+
+	// the passwords are never written in plain text without a key.
+	withKey(t, nil)
+	c.Tunnels = append(c.Tunnels, &Tunnel{ID: "b", Password: "new-secret"})
+	if err := c.Write(); !errors.Is(err, ErrNoSecretKey) {
+		t.Errorf("got error %v, want %v", err, ErrNoSecretKey)
+	}
+	if b2, _ := os.ReadFile(filename); string(b2) != string(b) {
+		t.Errorf("config is written without a key")
+	}
+
+	// the config is read-only while the passwords can not be decrypted.
+	withKey(t, testKey(1))
+	secretErr = ErrKeyMismatch
+	if err := c.Write(); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("got error %v, want %v", err, ErrKeyMismatch)
+	}
+}
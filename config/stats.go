@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	statsFile = "stats.json"
+	// StatsFlushInterval is the interval of persisting the stats, they are also persisted on shutdown.
+	StatsFlushInterval = time.Minute
+)
+
+// StatsStore holds the stats of the tunnels and entrypoints by ID,
+// it is persisted in its own file so that the config file only changes on user edits.
+type StatsStore struct {
+	Tunnels     map[string]ServiceStats `json:"tunnels,omitempty"`
+	EntryPoints map[string]ServiceStats `json:"entrypoints,omitempty"`
+}
+
+var (
+	stats   = &StatsStore{}
+	statsMu sync.RWMutex
+)
+
+func loadStats() error {
+	b, err := os.ReadFile(filepath.Join(configDir, statsFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	s := &StatsStore{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return err
+	}
+
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	stats = s
+
+	return nil
+}
+
+// TunnelStats returns the persisted stats of the tunnel.
+func TunnelStats(id string) ServiceStats {
+	statsMu.RLock()
+	defer statsMu.RUnlock()
+
+	return stats.Tunnels[id]
+}
+
+// EntryPointStats returns the persisted stats of the entrypoint.
+func EntryPointStats(id string) ServiceStats {
+	statsMu.RLock()
+	defer statsMu.RUnlock()
+
+	return stats.EntryPoints[id]
+}
+
+// SetTunnelStats replaces the stats of all the tunnels, they are persisted by SaveStats.
+func SetTunnelStats(m map[string]ServiceStats) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	stats.Tunnels = m
+}
+
+// SetEntryPointStats replaces the stats of all the entrypoints, they are persisted by SaveStats.
+func SetEntryPointStats(m map[string]ServiceStats) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	stats.EntryPoints = m
+}
+
+func SaveStats() error {
+	statsMu.RLock()
+	b, err := json.Marshal(stats)
+	statsMu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	return writeFileAtomic(filepath.Join(configDir, statsFile), b, 0600)
+}
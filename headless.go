@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-gost/gost.plus/api"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/metrics"
+	"github.com/go-gost/gost.plus/runner"
+	"github.com/go-gost/gost.plus/server"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/go-gost/gost.plus/tunnel/history"
+)
+
+// runHeadless runs the tunnels and entrypoints loaded from the config file until SIGINT or SIGTERM is received.
+// It fails if the passwords in the config can not be decrypted.
+func runHeadless() error {
+	if err := config.SecretError(); err != nil {
+		return fmt.Errorf("secret key: %w", err)
+	}
+
+	for i := 0; i < tunnel.Count(); i++ {
+		if tun := tunnel.GetIndex(i); tun != nil && !tun.IsClosed() {
+			slog.Info(fmt.Sprintf("tunnel %s: %s -> %s", tun.Name(), tun.Entrypoint(), tun.Endpoint()),
+				"id", tun.ID(), "type", tun.Type(), "err", tun.Err())
+		}
+	}
+	for i := 0; i < entrypoint.Count(); i++ {
+		if ep := entrypoint.GetIndex(i); ep != nil && !ep.IsClosed() {
+			slog.Info(fmt.Sprintf("entrypoint %s: %s -> %s", ep.Name(), ep.Entrypoint(), ep.Endpoint()),
+				"id", ep.ID(), "type", ep.Type(), "err", ep.Err())
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var tick <-chan time.Time
+	if statsInterval > 0 {
+		ticker := time.NewTicker(statsInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case e := <-runner.Event():
+			if e.Err != nil {
+				slog.Error(fmt.Sprintf("task: %s", e.Err), "task", e.TaskID)
+			}
+
+		case <-tick:
+			logStats()
+
+		case <-ctx.Done():
+			slog.Info("shutting down")
+
+			runner.Cancel(runner.TaskUpdateStats)
+			runner.Cancel(runner.TaskSupervise)
+			runner.Cancel(runner.TaskCheckHealth)
+			runner.Cancel(runner.TaskFlushStats)
+			api.Stop()
+			metrics.Stop()
+			server.Stop()
+
+			// save the config before closing, otherwise all the tunnels would be persisted as closed.
+			tunnel.SaveConfig()
+			entrypoint.SaveConfig()
+			tunnel.SaveStats()
+			entrypoint.SaveStats()
+			history.Save()
+			config.Unlock()
+
+			for i := 0; i < tunnel.Count(); i++ {
+				if tun := tunnel.GetIndex(i); tun != nil {
+					tun.Close()
+				}
+			}
+			for i := 0; i < entrypoint.Count(); i++ {
+				if ep := entrypoint.GetIndex(i); ep != nil {
+					ep.Close()
+				}
+			}
+			return nil
+		}
+	}
+}
+
+func logStats() {
+	for i := 0; i < tunnel.Count(); i++ {
+		tun := tunnel.GetIndex(i)
+		if tun == nil || tun.IsClosed() {
+			continue
+		}
+		logServiceStats("tunnel", tun)
+	}
+	for i := 0; i < entrypoint.Count(); i++ {
+		ep := entrypoint.GetIndex(i)
+		if ep == nil || ep.IsClosed() {
+			continue
+		}
+		logServiceStats("entrypoint", ep)
+	}
+	for _, t := range server.Tunnels() {
+		slog.Info(fmt.Sprintf("server tunnel %s stats", t.ID),
+			"hostnames", t.Hostnames,
+			"connectors", len(t.Connectors),
+			"currentConns", t.Stats.CurrentConns,
+			"totalConns", t.Stats.TotalConns,
+			"totalErrs", t.Stats.TotalErrs,
+			"inputBytes", t.Stats.InputBytes,
+			"outputBytes", t.Stats.OutputBytes,
+		)
+	}
+}
+
+func logServiceStats(kind string, tun tunnel.Tunnel) {
+	stats := tun.Stats()
+	slog.Info(fmt.Sprintf("%s %s stats", kind, tun.Name()),
+		"id", tun.ID(),
+		"type", tun.Type(),
+		"currentConns", stats.CurrentConns,
+		"totalConns", stats.TotalConns,
+		"totalErrs", stats.TotalErrs,
+		"requestRate", stats.RequestRate,
+		"inputBytes", stats.InputBytes,
+		"outputBytes", stats.OutputBytes,
+		"inputRateBytes", stats.InputRateBytes,
+		"outputRateBytes", stats.OutputRateBytes,
+		"err", tun.Err(),
+	)
+	if be, ok := tun.(tunnel.BackendErrorer); ok && be.BackendErr() != nil {
+		slog.Warn(fmt.Sprintf("%s %s: %s", kind, tun.Name(), be.BackendErr()), "id", tun.ID())
+	}
+}
@@ -2,31 +2,85 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	_ "net"
 	"os"
+	"strings"
 	"time"
 
 	"gioui.org/app"
 	_ "gioui.org/app/permission/storage"
 	"gioui.org/op"
 	"github.com/go-gost/core/logger"
+	"github.com/go-gost/gost.plus/api"
+	"github.com/go-gost/gost.plus/cli"
 	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/metrics"
 	"github.com/go-gost/gost.plus/runner"
 	"github.com/go-gost/gost.plus/runner/task"
+	"github.com/go-gost/gost.plus/server"
 	"github.com/go-gost/gost.plus/tunnel"
 	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/go-gost/gost.plus/tunnel/history"
 	"github.com/go-gost/gost.plus/ui"
+	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/page"
 	"github.com/go-gost/gost.plus/ui/theme"
 	"github.com/go-gost/gost.plus/ui/widget"
 	_ "github.com/go-gost/gost.plus/winres"
 )
 
+var (
+	headless       bool
+	statsInterval  time.Duration
+	passphraseFile string
+)
+
+func init() {
+	flag.BoolVar(&headless, "headless", false, "run the tunnels and entrypoints without GUI")
+	flag.DurationVar(&statsInterval, "stats", 10*time.Second, "interval of the stats logging in headless mode, 0 to disable")
+	flag.StringVar(&passphraseFile, "passphrase-file", "", "file containing the master passphrase which encrypts the passwords instead of the OS keyring, "+config.PassphraseEnv+" is used if it is not set")
+}
+
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [command]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output())
+		cli.Run(flag.CommandLine.Output(), []string{"help"})
+	}
+	flag.Parse()
+
+	if err := setPassphrase(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		if err := config.Init(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := cli.Run(os.Stdout, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	Init()
 
+	if headless {
+		if err := runHeadless(); err != nil {
+			config.Unlock()
+			logger.Default().Fatal(err)
+		}
+		return
+	}
+
 	go func() {
 		if err := run(); err != nil {
 			logger.Default().Fatal(err)
@@ -41,13 +95,43 @@ func run() error {
 
 	go handleEvent(ui)
 
+	if r := config.GetRecovery(); r != nil {
+		content := fmt.Sprintf(i18n.ConfigLost.Value(), r.Corrupted)
+		if r.Backup != "" {
+			content = fmt.Sprintf(i18n.ConfigRecovered.Value(), r.Backup)
+		}
+		ui.Router().Notify(widget.Message{
+			Type:    widget.Warn,
+			Content: content,
+		})
+	}
+	if err := config.SecretError(); err != nil {
+		desc := i18n.ConfigReadOnly
+		if errors.Is(err, config.ErrNoSecretKey) {
+			desc = i18n.NoSecretKey
+		}
+		ui.Router().Notify(widget.Message{
+			Type:    widget.Error,
+			Content: fmt.Sprintf(desc.Value(), err),
+		})
+	}
+
 	w := ui.Window()
 	var ops op.Ops
 	for {
-		switch e := w.Event().(type) {
+		evt := w.Event()
+		ui.Router().ListenEvents(evt)
+		switch e := evt.(type) {
 		case app.DestroyEvent:
+			api.Stop()
+			metrics.Stop()
+			server.Stop()
 			tunnel.SaveConfig()
 			entrypoint.SaveConfig()
+			tunnel.SaveStats()
+			entrypoint.SaveStats()
+			history.Save()
+			config.Unlock()
 			return e.Err
 		case app.FrameEvent:
 			gtx := app.NewContext(&ops, e)
@@ -58,6 +142,8 @@ func run() error {
 }
 
 func handleEvent(ui *ui.UI) {
+	// prompted are the fingerprints of the untrusted certificates the user has been asked about.
+	prompted := make(map[string]bool)
 	for {
 		select {
 		case e := <-ui.Router().Event():
@@ -81,18 +167,110 @@ func handleEvent(ui *ui.UI) {
 					})
 				}
 			}
+
+		case err := <-config.SecretErrors():
+			ui.Router().Notify(widget.Message{
+				Type:    widget.Error,
+				Content: fmt.Sprintf(i18n.NoSecretKey.Value(), err),
+			})
+
+		case e := <-tunnel.UntrustedCert():
+			if prompted[e.Fingerprint] {
+				break
+			}
+			if ui.Router().ShowDialog(trustDialog(ui, e)) {
+				prompted[e.Fingerprint] = true
+			}
 		}
 	}
 }
 
+// trustDialog asks the user to trust the certificate of the tunnel server on first use,
+// the tunnels and entrypoints are reloaded with the certificate pinned.
+func trustDialog(ui *ui.UI, e *tunnel.UntrustedCertError) *widget.Dialog {
+	desc := i18n.UntrustedCertDesc
+	if e.Changed {
+		desc = i18n.ChangedCertDesc
+	}
+	return &widget.Dialog{
+		Title: i18n.UntrustedCert,
+		Body:  fmt.Sprintf(desc.Value(), e.Addr, e.Subject, tunnel.FormatFingerprint(e.Fingerprint)),
+		Clicked: func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := tunnel.TrustServerCert(e.Fingerprint, e.Changed); err != nil {
+				ui.Router().Notify(widget.Message{
+					Type:    widget.Error,
+					Content: err.Error(),
+				})
+				return
+			}
+			tunnel.Reload()
+			entrypoint.Reload()
+			tunnel.SaveConfig()
+			entrypoint.SaveConfig()
+		},
+	}
+}
+
 func Init() {
-	config.Init()
+	if err := config.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := config.Lock(); err != nil {
+		fmt.Fprintf(os.Stderr, "lock: %s\n", err)
+		os.Exit(1)
+	}
 	tunnel.LoadConfig()
 	entrypoint.LoadConfig()
+	if err := history.Load(); err != nil {
+		slog.Error(fmt.Sprintf("load history: %s", err))
+	}
+
+	if err := api.Start(); err != nil {
+		slog.Error(fmt.Sprintf("api: %s", err))
+	}
+	if err := metrics.Start(); err != nil {
+		slog.Error(fmt.Sprintf("metrics: %s", err))
+	}
+	if err := server.Start(); err != nil {
+		slog.Error(fmt.Sprintf("server: %s", err))
+	}
 
 	runner.Exec(context.Background(), task.UpdateStats(),
 		runner.WithAync(true),
 		runner.WithInterval(time.Second),
 		runner.WithCancel(true),
 	)
+	runner.Exec(context.Background(), task.Supervise(),
+		runner.WithAync(true),
+		runner.WithInterval(time.Second),
+		runner.WithCancel(true),
+	)
+	runner.Exec(context.Background(), task.CheckHealth(),
+		runner.WithAync(true),
+		runner.WithInterval(tunnel.HealthCheckInterval),
+		runner.WithCancel(true),
+	)
+	runner.Exec(context.Background(), task.FlushStats(),
+		runner.WithAync(true),
+		runner.WithInterval(config.StatsFlushInterval),
+		runner.WithCancel(true),
+	)
+}
+
+// setPassphrase reads the master passphrase from the passphrase file or the environment variable.
+func setPassphrase() error {
+	passphrase := os.Getenv(config.PassphraseEnv)
+	if passphraseFile != "" {
+		b, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return err
+		}
+		passphrase = strings.TrimRight(string(b), "\r\n")
+	}
+	config.SetPassphrase(passphrase)
+	return nil
 }
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "gost_plus"
+
+	kindTunnel     = "tunnel"
+	kindEntrypoint = "entrypoint"
+)
+
+var (
+	labels = []string{"kind", "id", "name", "type"}
+
+	descUp            = newDesc("up", "Whether the service is running without error.")
+	descCurrentConns  = newDesc("current_connections", "Current number of connections.")
+	descTotalConns    = newDesc("connections_total", "Total number of connections.")
+	descTotalErrs     = newDesc("errors_total", "Total number of connection errors.")
+	descInputBytes    = newDesc("input_bytes_total", "Total number of received bytes.")
+	descOutputBytes   = newDesc("output_bytes_total", "Total number of sent bytes.")
+	descInputRate     = newDesc("input_rate_bytes", "Received bytes per second.")
+	descOutputRate    = newDesc("output_rate_bytes", "Sent bytes per second.")
+	descRequestRate   = newDesc("request_rate", "Connections per second.")
+	descRestarts      = newDesc("restarts_total", "Total number of automatic restarts.")
+	descHealthLatency = newDesc("health_latency_seconds", "Latency of connecting to the server in the latest health check.")
+	descHealthState   = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "service", "health_state"),
+		"Latest health check state of the tunnel, 1 for the current state.",
+		append(labels, "state"), nil,
+	)
+)
+
+func newDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "service", name), help, labels, nil)
+}
+
+// collector exports the stats of the tunnels and entrypoints on each scrape.
+type collector struct{}
+
+func newCollector() prometheus.Collector {
+	return &collector{}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	for i := 0; i < tunnel.Count(); i++ {
+		t := tunnel.GetIndex(i)
+		if t == nil {
+			continue
+		}
+		collect(ch, kindTunnel, t, tunnel.Restarts(t.ID()))
+
+		if t.IsClosed() {
+			continue
+		}
+		if health, ok := tunnel.GetHealth(t.ID()); ok {
+			values := []string{kindTunnel, t.ID(), t.Name(), t.Type()}
+			if health.ServerErr == nil {
+				ch <- prometheus.MustNewConstMetric(descHealthLatency, prometheus.GaugeValue, health.Latency.Seconds(), values...)
+			}
+			for _, state := range []tunnel.HealthState{tunnel.HealthUp, tunnel.HealthDegraded, tunnel.HealthDown} {
+				var v float64
+				if health.State == state {
+					v = 1
+				}
+				ch <- prometheus.MustNewConstMetric(descHealthState, prometheus.GaugeValue, v, append(values, string(state))...)
+			}
+		}
+	}
+
+	for i := 0; i < entrypoint.Count(); i++ {
+		ep := entrypoint.GetIndex(i)
+		if ep == nil {
+			continue
+		}
+		collect(ch, kindEntrypoint, ep, entrypoint.Restarts(ep.ID()))
+	}
+}
+
+func collect(ch chan<- prometheus.Metric, kind string, t tunnel.Tunnel, restarts int) {
+	values := []string{kind, t.ID(), t.Name(), t.Type()}
+
+	var up float64
+	if !t.IsClosed() && t.Err() == nil {
+		up = 1
+	}
+	ch <- prometheus.MustNewConstMetric(descUp, prometheus.GaugeValue, up, values...)
+	ch <- prometheus.MustNewConstMetric(descRestarts, prometheus.CounterValue, float64(restarts), values...)
+
+	stats := t.Stats()
+	ch <- prometheus.MustNewConstMetric(descCurrentConns, prometheus.GaugeValue, float64(stats.CurrentConns), values...)
+	ch <- prometheus.MustNewConstMetric(descTotalConns, prometheus.CounterValue, float64(stats.TotalConns), values...)
+	ch <- prometheus.MustNewConstMetric(descTotalErrs, prometheus.CounterValue, float64(stats.TotalErrs), values...)
+	ch <- prometheus.MustNewConstMetric(descInputBytes, prometheus.CounterValue, float64(stats.InputBytes), values...)
+	ch <- prometheus.MustNewConstMetric(descOutputBytes, prometheus.CounterValue, float64(stats.OutputBytes), values...)
+	ch <- prometheus.MustNewConstMetric(descInputRate, prometheus.GaugeValue, float64(stats.InputRateBytes), values...)
+	ch <- prometheus.MustNewConstMetric(descOutputRate, prometheus.GaugeValue, float64(stats.OutputRateBytes), values...)
+	ch <- prometheus.MustNewConstMetric(descRequestRate, prometheus.GaugeValue, stats.RequestRate, values...)
+}
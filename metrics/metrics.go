@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	DefaultAddr = "127.0.0.1:18965"
+	DefaultPath = "/metrics"
+)
+
+var (
+	server *http.Server
+	mu     sync.Mutex
+)
+
+// Start starts the metrics exporter if it is enabled in the config, the running exporter is stopped first.
+func Start() error {
+	Stop()
+
+	cfg := config.Get().Metrics
+	if cfg == nil || cfg.Addr == "" {
+		return nil
+	}
+
+	path := cfg.Path
+	if path == "" {
+		path = DefaultPath
+	}
+
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	log := logger.Default().WithFields(map[string]any{
+		"kind": "metrics",
+	})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(newCollector())
+
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	mu.Lock()
+	server = srv
+	mu.Unlock()
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error(err)
+		}
+	}()
+	log.Infof("metrics exporter listen on %s%s", ln.Addr(), path)
+
+	return nil
+}
+
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	server.Shutdown(ctx)
+	server = nil
+}
@@ -0,0 +1,232 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/google/uuid"
+)
+
+// IDPolicy decides how an imported tunnel or entrypoint whose ID already exists is handled.
+type IDPolicy int
+
+const (
+	// RegenerateID imports every tunnel with a new ID, so the public endpoint derived from the ID
+	// is not known to the author of the bundle. The entrypoints of the imported tunnels follow the new IDs,
+	// an existing entrypoint of another tunnel is skipped, since the entrypoint ID is the ID of the tunnel it connects to.
+	RegenerateID IDPolicy = iota
+	// PreserveID replaces the existing tunnel or entrypoint with the imported one.
+	PreserveID
+)
+
+// Result is the summary of an import.
+type Result struct {
+	Created  int `json:"created"`
+	Replaced int `json:"replaced"`
+	Skipped  int `json:"skipped"`
+}
+
+// registry adapts the tunnel and entrypoint packages to the same import path.
+type registry struct {
+	kind  string
+	get   func(id string) tunnel.Tunnel
+	add   func(t tunnel.Tunnel)
+	set   func(t tunnel.Tunnel)
+	save  func() error
+	build func(st string, opts tunnel.Options) tunnel.Tunnel
+	// validate checks the options before the tunnel or entrypoint is built.
+	validate func(st string, opts tunnel.Options) error
+	// config returns the config of the built tunnel or entrypoint.
+	config func(t tunnel.Tunnel) *config.Tunnel
+	// the entrypoint ID must be the ID of an existing tunnel.
+	requireID bool
+}
+
+var (
+	tunnels = &registry{
+		kind:     "tunnel",
+		get:      tunnel.Get,
+		add:      tunnel.Add,
+		set:      tunnel.Set,
+		save:     tunnel.SaveConfig,
+		build:    tunnel.NewTunnel,
+		validate: tunnel.ValidateOptions,
+		config:   tunnel.ConfigOf,
+	}
+	entrypoints = &registry{
+		kind:      "entrypoint",
+		get:       entrypoint.Get,
+		add:       entrypoint.Add,
+		set:       entrypoint.Set,
+		save:      entrypoint.SaveConfig,
+		build:     entrypoint.NewEntryPoint,
+		validate:  entrypoint.ValidateOptions,
+		config:    entrypoint.ConfigOf,
+		requireID: true,
+	}
+)
+
+// Import creates the tunnels and entrypoints of the bundle in the running client, the same way as they are created in the UI.
+// The invalid items are reported in the error while the others are still imported.
+func Import(b *Bundle, policy IDPolicy) (*Result, error) {
+	r := &Result{}
+
+	var errs []error
+	ids := make(map[string]string)
+	for _, v := range []struct {
+		reg   *registry
+		items []*Item
+	}{
+		{tunnels, b.Tunnels},
+		{entrypoints, b.EntryPoints},
+	} {
+		if len(v.items) == 0 {
+			continue
+		}
+		for _, item := range v.items {
+			if err := v.reg.importItem(r, item, policy, ids); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := v.reg.save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return r, errors.Join(errs...)
+}
+
+func (reg *registry) importItem(r *Result, item *Item, policy IDPolicy, ids map[string]string) error {
+	opts, replace, err := reg.resolve(item, policy, func(id string) bool { return reg.get(id) != nil }, ids)
+	if err != nil {
+		return err
+	}
+	if opts == nil {
+		r.Skipped++
+		return nil
+	}
+
+	t := reg.build(item.Type, *opts)
+	if t == nil {
+		return fmt.Errorf("%s %s: unknown type %s", reg.kind, item.Name, item.Type)
+	}
+
+	if replace {
+		old := reg.get(t.ID())
+		old.Close()
+		t.SetStats(old.Stats())
+		reg.set(t)
+		r.Replaced++
+	} else {
+		reg.add(t)
+		r.Created++
+	}
+
+	// the imported ones are never started, the user reviews what they expose and starts them.
+	t.Close()
+	return nil
+}
+
+// resolve validates the item and returns its options, replace reports whether the existing one with the same ID is replaced.
+// The options are nil if the item is skipped.
+// ids maps the IDs of the tunnels regenerated in the same import to the new ones, the entrypoints of them are remapped.
+func (reg *registry) resolve(item *Item, policy IDPolicy, exists func(id string) bool, ids map[string]string) (opts *tunnel.Options, replace bool, err error) {
+	if item == nil {
+		return
+	}
+
+	o := tunnel.OptionsOf(item.config())
+	o.ID = ""
+	opts = &o
+
+	if err := reg.validate(item.Type, *opts); err != nil {
+		return nil, false, fmt.Errorf("%s %s: %w", reg.kind, item.Name, err)
+	}
+
+	if item.ID != "" {
+		id, err := uuid.Parse(item.ID)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s %s: invalid ID %q", reg.kind, item.Name, item.ID)
+		}
+		opts.ID = id.String()
+		if v, ok := ids[opts.ID]; ok && reg.requireID {
+			opts.ID = v
+		}
+	} else if reg.requireID {
+		return nil, false, fmt.Errorf("%s %s: ID is required", reg.kind, item.Name)
+	}
+
+	if policy == RegenerateID && !reg.requireID {
+		id := uuid.New().String()
+		if opts.ID != "" {
+			ids[opts.ID] = id
+		}
+		opts.ID = id
+		return opts, false, nil
+	}
+
+	if opts.ID == "" || !exists(opts.ID) {
+		return opts, false, nil
+	}
+	if policy == PreserveID {
+		return opts, true, nil
+	}
+	return nil, false, nil
+}
+
+// ImportConfig adds the tunnels and entrypoints of the bundle to the config, it is used when the client is not running.
+// The config is not written.
+func ImportConfig(cfg *config.Config, b *Bundle, policy IDPolicy) (*Result, error) {
+	r := &Result{}
+
+	var errs []error
+	ids := make(map[string]string)
+	cfg.Tunnels = tunnels.importConfig(r, cfg.Tunnels, b.Tunnels, policy, ids, &errs)
+	cfg.EntryPoints = entrypoints.importConfig(r, cfg.EntryPoints, b.EntryPoints, policy, ids, &errs)
+
+	return r, errors.Join(errs...)
+}
+
+func (reg *registry) importConfig(r *Result, list []*config.Tunnel, items []*Item, policy IDPolicy, ids map[string]string, errs *[]error) []*config.Tunnel {
+	index := func(id string) int {
+		for i, c := range list {
+			if c != nil && c.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, item := range items {
+		opts, replace, err := reg.resolve(item, policy, func(id string) bool { return index(id) >= 0 }, ids)
+		if err != nil {
+			*errs = append(*errs, err)
+			continue
+		}
+		if opts == nil {
+			r.Skipped++
+			continue
+		}
+
+		t := reg.build(item.Type, *opts)
+		if t == nil {
+			*errs = append(*errs, fmt.Errorf("%s %s: unknown type %s", reg.kind, item.Name, item.Type))
+			continue
+		}
+		c := reg.config(t)
+		c.Closed = true
+
+		if replace {
+			i := index(c.ID)
+			c.Favorite = list[i].Favorite
+			list[i] = c
+			r.Replaced++
+		} else {
+			list = append(list, c)
+			r.Created++
+		}
+	}
+	return list
+}
@@ -0,0 +1,147 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/go-gost/gost.plus/config"
+	"github.com/google/uuid"
+)
+
+const (
+	tunnelID  = "5b1f7a9e-1111-4111-8111-111111111111"
+	otherID   = "5b1f7a9e-3333-4333-8333-333333333333"
+	unknownID = "5b1f7a9e-4444-4444-8444-444444444444"
+)
+
+func TestImportConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy IDPolicy
+		// the existing tunnels and entrypoints.
+		tunnels     []*config.Tunnel
+		entrypoints []*config.Tunnel
+		bundle      *Bundle
+		want        Result
+		err         bool
+		check       func(t *testing.T, cfg *config.Config)
+	}{
+		{
+			name:   "regenerate and remap",
+			policy: RegenerateID,
+			bundle: &Bundle{
+				Tunnels:     []*Item{{ID: tunnelID, Type: "tcp", Endpoint: "localhost:8080"}},
+				EntryPoints: []*Item{{ID: tunnelID, Type: "tcp", Endpoint: "localhost:9000"}},
+			},
+			want: Result{Created: 2},
+			check: func(t *testing.T, cfg *config.Config) {
+				id := cfg.Tunnels[0].ID
+				if id == tunnelID || uuid.Validate(id) != nil {
+					t.Errorf("got tunnel ID %s, want a new one", id)
+				}
+				if cfg.EntryPoints[0].ID != id {
+					t.Errorf("got entrypoint ID %s, want the new tunnel ID %s", cfg.EntryPoints[0].ID, id)
+				}
+			},
+		},
+		{
+			name:    "regenerate existing",
+			policy:  RegenerateID,
+			tunnels: []*config.Tunnel{{ID: tunnelID, Type: "tcp", Endpoint: "localhost:80", Favorite: true}},
+			bundle: &Bundle{
+				Tunnels: []*Item{{ID: tunnelID, Type: "tcp", Endpoint: "localhost:8080"}},
+			},
+			want: Result{Created: 1},
+			check: func(t *testing.T, cfg *config.Config) {
+				if len(cfg.Tunnels) != 2 || cfg.Tunnels[0].Endpoint != "localhost:80" || cfg.Tunnels[1].ID == tunnelID {
+					t.Errorf("got tunnels %+v %+v, want the existing one kept", cfg.Tunnels[0], cfg.Tunnels[1])
+				}
+			},
+		},
+		{
+			name:        "regenerate skips existing entrypoint",
+			policy:      RegenerateID,
+			entrypoints: []*config.Tunnel{{ID: otherID, Type: "tcp", Endpoint: "localhost:9000"}},
+			bundle: &Bundle{
+				EntryPoints: []*Item{
+					{ID: otherID, Type: "tcp", Endpoint: "localhost:9001"},
+					{ID: unknownID, Type: "tcp", Endpoint: "localhost:9002"},
+				},
+			},
+			want: Result{Created: 1, Skipped: 1},
+			check: func(t *testing.T, cfg *config.Config) {
+				if cfg.EntryPoints[0].Endpoint != "localhost:9000" || cfg.EntryPoints[1].ID != unknownID {
+					t.Errorf("got entrypoints %+v %+v", cfg.EntryPoints[0], cfg.EntryPoints[1])
+				}
+			},
+		},
+		{
+			name:    "preserve",
+			policy:  PreserveID,
+			tunnels: []*config.Tunnel{{ID: tunnelID, Type: "tcp", Endpoint: "localhost:80", Favorite: true}},
+			bundle: &Bundle{
+				Tunnels: []*Item{
+					{ID: tunnelID, Type: "tcp", Endpoint: "localhost:8080"},
+					{ID: otherID, Type: "tcp", Endpoint: "localhost:8081"},
+					{Type: "tcp", Endpoint: "localhost:8082"},
+				},
+			},
+			want: Result{Created: 2, Replaced: 1},
+			check: func(t *testing.T, cfg *config.Config) {
+				if c := cfg.Tunnels[0]; c.ID != tunnelID || c.Endpoint != "localhost:8080" || !c.Favorite {
+					t.Errorf("got replaced tunnel %+v", c)
+				}
+				if c := cfg.Tunnels[1]; c.ID != otherID {
+					t.Errorf("got tunnel ID %s, want %s", c.ID, otherID)
+				}
+				if c := cfg.Tunnels[2]; uuid.Validate(c.ID) != nil {
+					t.Errorf("got tunnel ID %s, want a new one", c.ID)
+				}
+			},
+		},
+		{
+			name:   "invalid items",
+			policy: RegenerateID,
+			bundle: &Bundle{
+				Tunnels: []*Item{
+					{ID: "not-a-uuid", Type: "tcp", Endpoint: "localhost:8080"},
+					{Type: "unknown"},
+					{Type: "http", Endpoint: "localhost:8080", Routes: []config.HTTPRoute{{Path: "api", Endpoint: "localhost:8081"}}},
+					{Type: "advanced", Config: "services:\n- name: a\n  addr: :1080\n  handler:\n    type: socks5\n  listener:\n    type: tcp\n  metadata:\n    netns: host\n"},
+					{Type: "tcp", Endpoint: "localhost:8080"},
+					nil,
+				},
+				EntryPoints: []*Item{
+					{Type: "tcp", Endpoint: "localhost:9000"},
+					{ID: otherID, Type: "file", Endpoint: "0.0.0.0:9000"},
+				},
+			},
+			want: Result{Created: 1, Skipped: 1},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Tunnels:     tt.tunnels,
+				EntryPoints: tt.entrypoints,
+			}
+			r, err := ImportConfig(cfg, tt.bundle, tt.policy)
+			if (err != nil) != tt.err {
+				t.Errorf("got error %v, want error %v", err, tt.err)
+			}
+			if *r != tt.want {
+				t.Errorf("got %+v, want %+v", *r, tt.want)
+			}
+
+			for _, c := range append(cfg.Tunnels[len(tt.tunnels):], cfg.EntryPoints[len(tt.entrypoints):]...) {
+				if !c.Closed {
+					t.Errorf("imported %s %s is not closed", c.Type, c.ID)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
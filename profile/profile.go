@@ -0,0 +1,247 @@
+package profile
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/go-gost/gost.plus/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Version is the current version of the bundle format.
+	Version = 1
+	// Scheme is the URI scheme of the bundles shared as a link or QR code.
+	Scheme = "gost-plus"
+	// MaxBundleSize is the maximum size of a decoded bundle, it limits the decompression of a link.
+	MaxBundleSize = 1 << 20
+)
+
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatURI  Format = "uri"
+)
+
+var (
+	ErrInvalidBundle = errors.New("invalid bundle")
+	ErrEmptyBundle   = errors.New("empty bundle")
+	ErrBundleTooBig  = errors.New("bundle is too big")
+)
+
+// Bundle is a portable set of tunnels and entrypoints.
+type Bundle struct {
+	Version     int     `yaml:"version" json:"version"`
+	Tunnels     []*Item `yaml:"tunnels,omitempty" json:"tunnels,omitempty"`
+	EntryPoints []*Item `yaml:"entrypoints,omitempty" json:"entrypoints,omitempty"`
+}
+
+// Item is the portable part of config.Tunnel, the local state such as stats is not exported.
+type Item struct {
+	ID         string              `yaml:"id,omitempty" json:"id,omitempty"`
+	Name       string              `yaml:"name,omitempty" json:"name,omitempty"`
+	Type       string              `yaml:"type" json:"type"`
+	Endpoint   string              `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	Strategy   string              `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+	Routes     []config.HTTPRoute  `yaml:"routes,omitempty" json:"routes,omitempty"`
+	ReqHeaders []config.HTTPHeader `yaml:"requestHeaders,omitempty" json:"requestHeaders,omitempty"`
+	ResHeaders []config.HTTPHeader `yaml:"responseHeaders,omitempty" json:"responseHeaders,omitempty"`
+	Hostname   string              `yaml:"hostname,omitempty" json:"hostname,omitempty"`
+	Username   string              `yaml:"username,omitempty" json:"username,omitempty"`
+	Password   string              `yaml:"password,omitempty" json:"password,omitempty"`
+	EnableTLS  bool                `yaml:"enableTLS,omitempty" json:"enableTLS,omitempty"`
+	TLS        *config.TLS         `yaml:"tls,omitempty" json:"tls,omitempty"`
+	Keepalive  bool                `yaml:"keepalive,omitempty" json:"keepalive,omitempty"`
+	TTL        int                 `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	Transport  *config.Transport   `yaml:"transport,omitempty" json:"transport,omitempty"`
+	Proxies    []config.Proxy      `yaml:"proxies,omitempty" json:"proxies,omitempty"`
+	Config     string              `yaml:"config,omitempty" json:"config,omitempty"`
+}
+
+// Export creates a bundle from the tunnels and entrypoints, the passwords are removed if stripSecrets is true.
+func Export(tunnels, entrypoints []*config.Tunnel, stripSecrets bool) *Bundle {
+	b := &Bundle{
+		Version: Version,
+	}
+	for _, c := range tunnels {
+		if item := newItem(c, stripSecrets); item != nil {
+			b.Tunnels = append(b.Tunnels, item)
+		}
+	}
+	for _, c := range entrypoints {
+		if item := newItem(c, stripSecrets); item != nil {
+			b.EntryPoints = append(b.EntryPoints, item)
+		}
+	}
+	return b
+}
+
+func newItem(c *config.Tunnel, stripSecrets bool) *Item {
+	if c == nil {
+		return nil
+	}
+	item := &Item{
+		ID:         c.ID,
+		Name:       c.Name,
+		Type:       c.Type,
+		Endpoint:   c.Endpoint,
+		Strategy:   c.Strategy,
+		Routes:     c.Routes,
+		ReqHeaders: c.RequestHeaders,
+		ResHeaders: c.ResponseHeaders,
+		Hostname:   c.Hostname,
+		Username:   c.Username,
+		Password:   c.Password,
+		EnableTLS:  c.EnableTLS,
+		TLS:        c.TLS,
+		Keepalive:  c.Keepalive,
+		TTL:        c.TTL,
+		Transport:  c.Transport,
+		Proxies:    c.Proxies,
+		Config:     c.Config,
+	}
+	if stripSecrets {
+		item.Password = ""
+		item.Proxies = nil
+		for _, p := range c.Proxies {
+			p.Password = ""
+			item.Proxies = append(item.Proxies, p)
+		}
+	}
+	return item
+}
+
+// config returns the config of the item, the inverse of newItem.
+func (item *Item) config() *config.Tunnel {
+	return &config.Tunnel{
+		ID:              item.ID,
+		Name:            item.Name,
+		Type:            item.Type,
+		Endpoint:        item.Endpoint,
+		Strategy:        item.Strategy,
+		Routes:          item.Routes,
+		RequestHeaders:  item.ReqHeaders,
+		ResponseHeaders: item.ResHeaders,
+		Hostname:        item.Hostname,
+		Username:        item.Username,
+		Password:        item.Password,
+		EnableTLS:       item.EnableTLS,
+		TLS:             item.TLS,
+		Keepalive:       item.Keepalive,
+		TTL:             item.TTL,
+		Transport:       item.Transport,
+		Proxies:         item.Proxies,
+		Config:          item.Config,
+	}
+}
+
+// Encode encodes the bundle in the format.
+// The URI holds the compressed JSON bundle, so that it is short enough for a QR code.
+func Encode(b *Bundle, format Format) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(b); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+
+	case FormatJSON:
+		return json.MarshalIndent(b, "", "  ")
+
+	case FormatURI:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		w, err := flate.NewWriter(&buf, flate.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		u := url.URL{
+			Scheme:   Scheme,
+			Host:     "import",
+			RawQuery: url.Values{"data": {base64.RawURLEncoding.EncodeToString(buf.Bytes())}}.Encode(),
+		}
+		return []byte(u.String()), nil
+
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
+
+// Decode decodes the bundle in any of the formats.
+func Decode(data []byte) (*Bundle, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, ErrEmptyBundle
+	}
+	if len(data) > MaxBundleSize {
+		return nil, ErrBundleTooBig
+	}
+
+	b := &Bundle{}
+	switch {
+	case bytes.HasPrefix(data, []byte(Scheme+"://")):
+		u, err := url.Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		v, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(u.Query().Get("data"), "="))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		r := flate.NewReader(bytes.NewReader(v))
+		defer r.Close()
+		if data, err = io.ReadAll(io.LimitReader(r, MaxBundleSize+1)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if len(data) > MaxBundleSize {
+			return nil, ErrBundleTooBig
+		}
+		if err := json.Unmarshal(data, b); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+
+	case data[0] == '{':
+		if err := json.Unmarshal(data, b); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+
+	default:
+		if err := yaml.Unmarshal(data, b); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+	}
+
+	if b.Version > Version {
+		return nil, fmt.Errorf("bundle version %d is newer than the supported version %d", b.Version, Version)
+	}
+	if len(b.Tunnels) == 0 && len(b.EntryPoints) == 0 {
+		return nil, ErrEmptyBundle
+	}
+	return b, nil
+}
@@ -0,0 +1,139 @@
+package profile
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-gost/gost.plus/config"
+)
+
+func testBundle() *Bundle {
+	return &Bundle{
+		Version: Version,
+		Tunnels: []*Item{
+			{
+				ID:       "5b1f7a9e-1111-4111-8111-111111111111",
+				Name:     "web",
+				Type:     "http",
+				Endpoint: "localhost:8080",
+				Routes:   []config.HTTPRoute{{Path: "/api", StripPrefix: true, Endpoint: "localhost:8081"}},
+				Username: "user",
+				Password: "pass",
+			},
+		},
+		EntryPoints: []*Item{
+			{
+				ID:       "5b1f7a9e-2222-4222-8222-222222222222",
+				Name:     "db",
+				Type:     "tcp",
+				Endpoint: "localhost:5432",
+			},
+		},
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, format := range []Format{FormatYAML, FormatJSON, FormatURI} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := Encode(testBundle(), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format == FormatURI && !strings.HasPrefix(string(data), Scheme+"://import?data=") {
+				t.Errorf("got URI %s", data)
+			}
+
+			b, err := Decode(append([]byte("\n "), data...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(b, testBundle()) {
+				t.Errorf("got %+v, want %+v", b, testBundle())
+			}
+		})
+	}
+
+	if _, err := Encode(testBundle(), "xml"); err == nil {
+		t.Error("unknown format is encoded")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{name: "empty", data: " \n", err: ErrEmptyBundle},
+		{name: "no items", data: "version: 1\n", err: ErrEmptyBundle},
+		{name: "invalid JSON", data: "{\"tunnels\": [", err: ErrInvalidBundle},
+		{name: "invalid YAML", data: "tunnels: [", err: ErrInvalidBundle},
+		{name: "invalid URI data", data: Scheme + "://import?data=%%%", err: ErrInvalidBundle},
+		{name: "invalid compressed data", data: Scheme + "://import?data=" + base64.RawURLEncoding.EncodeToString([]byte("not deflate")), err: ErrInvalidBundle},
+		{name: "too big", data: "# " + strings.Repeat("x", MaxBundleSize), err: ErrBundleTooBig},
+		{name: "decompressed too big", data: deflateURI(t, []byte("{\"version\": 1, \"x\": \""+strings.Repeat("x", MaxBundleSize)+"\"}")), err: ErrBundleTooBig},
+		{name: "newer version", data: fmt.Sprintf("version: %d\ntunnels:\n- type: tcp\n", Version+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Decode([]byte(tt.data))
+			if err == nil {
+				t.Fatalf("got %+v, want an error", b)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func deflateURI(t *testing.T, data []byte) string {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(data)
+	w.Close()
+
+	u := url.URL{
+		Scheme:   Scheme,
+		Host:     "import",
+		RawQuery: url.Values{"data": {base64.RawURLEncoding.EncodeToString(buf.Bytes())}}.Encode(),
+	}
+	return u.String()
+}
+
+func TestExportStripSecrets(t *testing.T) {
+	c := &config.Tunnel{
+		ID:       "5b1f7a9e-1111-4111-8111-111111111111",
+		Type:     "tcp",
+		Username: "user",
+		Password: "pass",
+		Proxies:  []config.Proxy{{Addr: "proxy:3128", Username: "u", Password: "p"}},
+	}
+
+	b := Export([]*config.Tunnel{c, nil}, nil, true)
+	if len(b.Tunnels) != 1 {
+		t.Fatalf("got %d tunnels, want 1", len(b.Tunnels))
+	}
+	item := b.Tunnels[0]
+	if item.Password != "" || item.Proxies[0].Password != "" || item.Username != "user" || item.Proxies[0].Username != "u" {
+		t.Errorf("got %+v, want the passwords removed", item)
+	}
+	if c.Password != "pass" || c.Proxies[0].Password != "p" {
+		t.Errorf("the exported config is changed")
+	}
+
+	if item := Export([]*config.Tunnel{c}, nil, false).Tunnels[0]; item.Password != "pass" || item.Proxies[0].Password != "p" {
+		t.Errorf("got %+v, want the passwords kept", item)
+	}
+}
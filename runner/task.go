@@ -8,6 +8,9 @@ type TaskID string
 
 const (
 	TaskUpdateStats TaskID = "service.stats.update"
+	TaskSupervise   TaskID = "service.supervise"
+	TaskCheckHealth TaskID = "service.health.check"
+	TaskFlushStats  TaskID = "service.stats.flush"
 )
 
 type Task interface {
@@ -0,0 +1,26 @@
+package task
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-gost/gost.plus/runner"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/go-gost/gost.plus/tunnel/history"
+)
+
+type flushStatsTask struct{}
+
+// FlushStats returns a task persisting the stats and the traffic history of the tunnels and entrypoints.
+func FlushStats() runner.Task {
+	return &flushStatsTask{}
+}
+
+func (t *flushStatsTask) ID() runner.TaskID {
+	return runner.TaskFlushStats
+}
+
+func (t *flushStatsTask) Run(context.Context) error {
+	return errors.Join(tunnel.SaveStats(), entrypoint.SaveStats(), history.Save())
+}
@@ -0,0 +1,24 @@
+package task
+
+import (
+	"context"
+
+	"github.com/go-gost/gost.plus/runner"
+	"github.com/go-gost/gost.plus/tunnel"
+)
+
+type checkHealthTask struct{}
+
+// CheckHealth returns a task checking the connection to the server and the local endpoint of each tunnel.
+func CheckHealth() runner.Task {
+	return &checkHealthTask{}
+}
+
+func (t *checkHealthTask) ID() runner.TaskID {
+	return runner.TaskCheckHealth
+}
+
+func (t *checkHealthTask) Run(ctx context.Context) error {
+	tunnel.CheckHealth(ctx)
+	return nil
+}
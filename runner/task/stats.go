@@ -9,6 +9,7 @@ import (
 	"github.com/go-gost/gost.plus/runner"
 	"github.com/go-gost/gost.plus/tunnel"
 	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/go-gost/gost.plus/tunnel/history"
 )
 
 type updateStatsTask struct{}
@@ -48,7 +49,11 @@ func (t *updateStatsTask) updateTunnel() error {
 
 		stats := config.ServiceStats{}
 
-		if s := status.Stats(); s != nil {
+		s := status.Stats()
+		if ms, ok := tun.(tunnel.MultiService); ok {
+			s = ms.ServiceStats()
+		}
+		if s != nil {
 			stats.CurrentConns = s.Get(stats_pkg.KindCurrentConns)
 			stats.InputBytes = s.Get(stats_pkg.KindInputBytes)
 			stats.OutputBytes = s.Get(stats_pkg.KindOutputBytes)
@@ -76,9 +81,10 @@ func (t *updateStatsTask) updateTunnel() error {
 		stats.RequestRate = float64(reqRate) / d.Seconds()
 
 		tun.SetStats(stats)
+		history.Tunnels.Get(tun.ID()).Add(sample(stats))
 	}
 
-	return tunnel.SaveConfig()
+	return nil
 }
 
 func (t *updateStatsTask) updateEntrypoint() error {
@@ -130,7 +136,18 @@ func (t *updateStatsTask) updateEntrypoint() error {
 		stats.RequestRate = float64(reqRate) / d.Seconds()
 
 		ep.SetStats(stats)
+		history.EntryPoints.Get(ep.ID()).Add(sample(stats))
 	}
 
-	return entrypoint.SaveConfig()
+	return nil
+}
+
+func sample(stats config.ServiceStats) history.Sample {
+	return history.Sample{
+		Time:            stats.Time,
+		InputRateBytes:  stats.InputRateBytes,
+		OutputRateBytes: stats.OutputRateBytes,
+		RequestRate:     stats.RequestRate,
+		CurrentConns:    stats.CurrentConns,
+	}
 }
@@ -0,0 +1,26 @@
+package task
+
+import (
+	"context"
+
+	"github.com/go-gost/gost.plus/runner"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+)
+
+type superviseTask struct{}
+
+// Supervise returns a task restarting the failed tunnels and entrypoints.
+func Supervise() runner.Task {
+	return &superviseTask{}
+}
+
+func (t *superviseTask) ID() runner.TaskID {
+	return runner.TaskSupervise
+}
+
+func (t *superviseTask) Run(context.Context) error {
+	tunnel.Supervise()
+	entrypoint.Supervise()
+	return nil
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/ingress"
+	"github.com/go-gost/core/observer"
+	"github.com/go-gost/core/sd"
+	"github.com/go-gost/gost.plus/config"
+	xstats "github.com/go-gost/x/observer/stats"
+)
+
+// Tunnel is a tunnel connected to the server.
+type Tunnel struct {
+	ID string `json:"id"`
+	// Hostnames are routed to the tunnel by the ingress.
+	Hostnames []string `json:"hostnames,omitempty"`
+	// Connectors are the connections of the clients running the tunnel.
+	Connectors  []Connector `json:"connectors"`
+	ConnectedAt time.Time   `json:"connectedAt"`
+	Stats       Stats       `json:"stats"`
+}
+
+// Connector is a connection of a client to the server, a tunnel run by several clients has several connectors.
+type Connector struct {
+	ID          string    `json:"id"`
+	Network     string    `json:"network"`
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+// Stats is the traffic of the tunnel through the server.
+type Stats struct {
+	TotalConns   uint64 `json:"totalConns"`
+	CurrentConns uint64 `json:"currentConns"`
+	InputBytes   uint64 `json:"inputBytes"`
+	OutputBytes  uint64 `json:"outputBytes"`
+	TotalErrs    uint64 `json:"totalErrs"`
+}
+
+// registry keeps the connected tunnels and the ingress rules of the server.
+// It is the service discovery, the ingress and the stats observer of the tunnel handler.
+type registry struct {
+	tunnels map[string]*Tunnel
+	// rules are the hostnames requested by the tunnels, static are the rules in the config.
+	rules  map[string]string
+	static map[string]string
+	mu     sync.RWMutex
+}
+
+var tunnels = &registry{}
+
+// reset removes the tunnels and sets the static ingress rules.
+func (r *registry) reset(rules []config.IngressRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tunnels = make(map[string]*Tunnel)
+	r.rules = make(map[string]string)
+	r.static = make(map[string]string)
+	for _, rule := range rules {
+		r.static[strings.ToLower(rule.Hostname)] = rule.Tunnel
+	}
+}
+
+func (r *registry) list() []Tunnel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var list []Tunnel
+	for _, t := range r.tunnels {
+		tun := *t
+		tun.Connectors = slices.Clone(t.Connectors)
+		tun.Hostnames = r.hostnames(t.ID)
+		list = append(list, tun)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ConnectedAt.Before(list[j].ConnectedAt)
+	})
+	return list
+}
+
+// hostnames returns the hostnames routed to the tunnel, the caller holds the lock.
+func (r *registry) hostnames(id string) []string {
+	var hosts []string
+	for _, m := range []map[string]string{r.static, r.rules} {
+		for host, tid := range m {
+			if tid == id && !slices.Contains(hosts, host) {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func (r *registry) Register(ctx context.Context, service *sd.Service, opts ...sd.Option) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tunnels == nil {
+		r.tunnels = make(map[string]*Tunnel)
+	}
+	t := r.tunnels[service.Name]
+	if t == nil {
+		t = &Tunnel{
+			ID:          service.Name,
+			ConnectedAt: time.Now(),
+		}
+		r.tunnels[service.Name] = t
+	}
+	t.Connectors = append(t.Connectors, Connector{
+		ID:          service.ID,
+		Network:     service.Network,
+		ConnectedAt: time.Now(),
+	})
+	return nil
+}
+
+// Deregister removes the connector, the tunnel and its requested hostnames are removed with the last connector.
+func (r *registry) Deregister(ctx context.Context, service *sd.Service) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.tunnels[service.Name]
+	if t == nil {
+		return nil
+	}
+	t.Connectors = slices.DeleteFunc(t.Connectors, func(c Connector) bool {
+		return c.ID == service.ID
+	})
+	if len(t.Connectors) > 0 {
+		return nil
+	}
+
+	delete(r.tunnels, service.Name)
+	for host, tid := range r.rules {
+		if tid == service.Name {
+			delete(r.rules, host)
+		}
+	}
+	return nil
+}
+
+func (r *registry) Renew(ctx context.Context, service *sd.Service) error {
+	return nil
+}
+
+// Get returns no services, the tunnels are not looked up on other servers.
+func (r *registry) Get(ctx context.Context, name string) ([]*sd.Service, error) {
+	return nil, nil
+}
+
+func (r *registry) SetRule(ctx context.Context, rule *ingress.Rule, opts ...ingress.Option) bool {
+	if rule == nil || rule.Hostname == "" || rule.Endpoint == "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rules == nil {
+		r.rules = make(map[string]string)
+	}
+	r.rules[strings.ToLower(rule.Hostname)] = rule.Endpoint
+	return true
+}
+
+// GetRule looks up the host in the rules, then the first label of the host,
+// which is the endpoint of the tunnel in <endpoint>.<domain>.
+func (r *registry) GetRule(ctx context.Context, host string, opts ...ingress.Option) *ingress.Rule {
+	if v, _, err := net.SplitHostPort(host); err == nil {
+		host = v
+	}
+	host = strings.ToLower(host)
+	if host == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	label, _, _ := strings.Cut(host, ".")
+	for _, h := range []string{host, label} {
+		for _, m := range []map[string]string{r.static, r.rules} {
+			if tid, ok := m[h]; ok {
+				return &ingress.Rule{Hostname: h, Endpoint: tid}
+			}
+		}
+	}
+	return nil
+}
+
+// Observe updates the stats of the tunnels.
+func (r *registry) Observe(ctx context.Context, events []observer.Event, opts ...observer.Option) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range events {
+		ev, ok := e.(xstats.StatsEvent)
+		if !ok {
+			continue
+		}
+		if t := r.tunnels[ev.Client]; t != nil {
+			t.Stats = Stats{
+				TotalConns:   ev.TotalConns,
+				CurrentConns: ev.CurrentConns,
+				InputBytes:   ev.InputBytes,
+				OutputBytes:  ev.OutputBytes,
+				TotalErrs:    ev.TotalErrs,
+			}
+		}
+	}
+	return nil
+}
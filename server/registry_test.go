@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/go-gost/core/ingress"
+	"github.com/go-gost/core/sd"
+	"github.com/go-gost/gost.plus/config"
+)
+
+func TestRegistryGetRule(t *testing.T) {
+	r := &registry{}
+	r.reset([]config.IngressRule{
+		{Hostname: "App.Example.com", Tunnel: "static"},
+		{Hostname: "shared", Tunnel: "static"},
+	})
+	r.SetRule(context.Background(), &ingress.Rule{Hostname: "api.example.com", Endpoint: "requested"})
+	r.SetRule(context.Background(), &ingress.Rule{Hostname: "Shared", Endpoint: "requested"})
+	r.SetRule(context.Background(), &ingress.Rule{Hostname: "web", Endpoint: "requested"})
+
+	tests := []struct {
+		host     string
+		hostname string
+		endpoint string
+	}{
+		{host: "app.example.com", hostname: "app.example.com", endpoint: "static"},
+		{host: "APP.example.com:8080", hostname: "app.example.com", endpoint: "static"},
+		{host: "api.example.com", hostname: "api.example.com", endpoint: "requested"},
+		// static rules take precedence over the requested ones.
+		{host: "shared.example.com", hostname: "shared", endpoint: "static"},
+		{host: "web.example.com", hostname: "web", endpoint: "requested"},
+		{host: "web", hostname: "web", endpoint: "requested"},
+		{host: "web.example.com:443", hostname: "web", endpoint: "requested"},
+		{host: "www.web.example.com"},
+		{host: "unknown.example.com"},
+		{host: ""},
+		{host: ":80"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			rule := r.GetRule(context.Background(), tt.host)
+			if tt.endpoint == "" {
+				if rule != nil {
+					t.Errorf("got rule %+v, want none", rule)
+				}
+				return
+			}
+			if rule == nil {
+				t.Fatalf("got no rule, want %s", tt.endpoint)
+			}
+			if rule.Hostname != tt.hostname || rule.Endpoint != tt.endpoint {
+				t.Errorf("got %s -> %s, want %s -> %s", rule.Hostname, rule.Endpoint, tt.hostname, tt.endpoint)
+			}
+		})
+	}
+}
+
+func TestRegistrySetRule(t *testing.T) {
+	r := &registry{}
+	for _, rule := range []*ingress.Rule{nil, {Hostname: "web"}, {Endpoint: "tunnel"}} {
+		if r.SetRule(context.Background(), rule) {
+			t.Errorf("rule %+v is set", rule)
+		}
+	}
+	if !r.SetRule(context.Background(), &ingress.Rule{Hostname: "web", Endpoint: "tunnel"}) {
+		t.Error("rule is not set")
+	}
+}
+
+func TestRegistryDeregister(t *testing.T) {
+	ctx := context.Background()
+
+	r := &registry{}
+	r.reset([]config.IngressRule{{Hostname: "static", Tunnel: "tunnel"}})
+	r.Register(ctx, &sd.Service{ID: "c1", Name: "tunnel", Network: "tcp"})
+	r.Register(ctx, &sd.Service{ID: "c2", Name: "tunnel", Network: "udp"})
+	r.SetRule(ctx, &ingress.Rule{Hostname: "web", Endpoint: "tunnel"})
+
+	list := r.list()
+	if len(list) != 1 || len(list[0].Connectors) != 2 {
+		t.Fatalf("got %+v, want one tunnel with two connectors", list)
+	}
+	if want := []string{"static", "web"}; !slices.Equal(list[0].Hostnames, want) {
+		t.Errorf("got hostnames %v, want %v", list[0].Hostnames, want)
+	}
+
+	r.Deregister(ctx, &sd.Service{ID: "c1", Name: "tunnel"})
+	if rule := r.GetRule(ctx, "web"); rule == nil {
+		t.Error("requested rule is removed with a connector left")
+	}
+
+	r.Deregister(ctx, &sd.Service{ID: "c2", Name: "tunnel"})
+	if list := r.list(); len(list) != 0 {
+		t.Errorf("got %d tunnels after the last connector is removed", len(list))
+	}
+	if rule := r.GetRule(ctx, "web"); rule != nil {
+		t.Error("requested rule is kept after the tunnel is removed")
+	}
+	if rule := r.GetRule(ctx, "static"); rule == nil {
+		t.Error("static rule is removed with the tunnel")
+	}
+}
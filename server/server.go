@@ -0,0 +1,317 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/service"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/tunnel"
+	xconfig "github.com/go-gost/x/config"
+	service_parser "github.com/go-gost/x/config/parsing/service"
+	xregistry "github.com/go-gost/x/registry"
+	"github.com/google/uuid"
+
+	_ "github.com/go-gost/x/handler/tunnel"
+
+	// The listeners of the tunnel.Transports.
+	_ "github.com/go-gost/x/listener/grpc"
+	_ "github.com/go-gost/x/listener/http2/h2"
+	_ "github.com/go-gost/x/listener/kcp"
+	_ "github.com/go-gost/x/listener/quic"
+	_ "github.com/go-gost/x/listener/tls"
+	_ "github.com/go-gost/x/listener/ws"
+)
+
+const (
+	DefaultAddr       = ":8443"
+	DefaultEntrypoint = ":8080"
+
+	// name of the service, and of the ingress, service discovery and observer of the tunnel handler.
+	name = "gost.plus-server"
+
+	certFile = "server-cert.pem"
+	keyFile  = "server-key.pem"
+)
+
+func init() {
+	xregistry.IngressRegistry().Register(name, tunnels)
+	xregistry.SDRegistry().Register(name, tunnels)
+	xregistry.ObserverRegistry().Register(name, tunnels)
+}
+
+var (
+	svc service.Service
+	// fingerprint is the SHA-256 fingerprint of the certificate of the running server.
+	fingerprint string
+	mu          sync.Mutex
+)
+
+// Start starts the tunnel server if it is enabled in the config, the running server is stopped first.
+func Start() error {
+	Stop()
+
+	cfg := config.Get().Server
+	if cfg == nil || cfg.Addr == "" {
+		return nil
+	}
+	if err := Validate(cfg); err != nil {
+		return err
+	}
+
+	svcCfg, err := serviceConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	fp, err := certFingerprint(svcCfg.Listener.TLS.CertFile)
+	if err != nil {
+		return err
+	}
+
+	tunnels.reset(cfg.Ingress)
+	s, err := service_parser.ParseService(svcCfg)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	svc = s
+	fingerprint = fp
+	mu.Unlock()
+
+	log := logger.Default().WithFields(map[string]any{
+		"kind": "server",
+	})
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return svc != s
+	}
+	go func() {
+		// The h2 listener returns a nil conn without an error once it is closed, the service panics on it.
+		defer func() {
+			if v := recover(); v != nil && !stopped() {
+				log.Errorf("tunnel server: %v", v)
+			}
+		}()
+
+		if err := s.Serve(); err != nil && !stopped() {
+			log.Error(err)
+		}
+	}()
+	log.Infof("tunnel server listen on %s (%s), entrypoint %s, certificate SHA-256 %s",
+		s.Addr(), svcCfg.Listener.Type, cfg.Entrypoint, tunnel.FormatFingerprint(fp))
+
+	return nil
+}
+
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if svc == nil {
+		return
+	}
+	svc.Close()
+	svc = nil
+	fingerprint = ""
+}
+
+// Running reports whether the tunnel server is running.
+func Running() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return svc != nil
+}
+
+// Fingerprint returns the SHA-256 fingerprint of the server certificate, the clients pin it to trust a self-signed certificate.
+// It is empty if the server is not running.
+func Fingerprint() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return fingerprint
+}
+
+// Tunnels returns the tunnels connected to the server, in the order they are connected.
+func Tunnels() []Tunnel {
+	if !Running() {
+		return nil
+	}
+	return tunnels.list()
+}
+
+// Validate checks the server config, the transport must be compiled in and the addresses and PEM files must be valid.
+func Validate(cfg *config.ServerConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	for _, addr := range []string{cfg.Addr, cfg.Entrypoint} {
+		if addr == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid address %q", addr)
+		}
+	}
+	if t := cfg.Transport; t != nil && t.Type != "" {
+		if !slices.Contains(tunnel.Transports, t.Type) {
+			return fmt.Errorf("unknown transport %s, available transports are %s", t.Type, strings.Join(tunnel.Transports, ", "))
+		}
+		if !xregistry.ListenerRegistry().IsRegistered(t.Type) {
+			return fmt.Errorf("transport %s is not compiled in", t.Type)
+		}
+		if t.Path != "" && !strings.HasPrefix(t.Path, "/") {
+			return fmt.Errorf("path %q does not start with a '/'", t.Path)
+		}
+	}
+	if (cfg.Cert == "") != (cfg.Key == "") {
+		return fmt.Errorf("certificate: both the certificate and the key are required")
+	}
+	if err := tunnel.ValidateTLS(&config.TLS{CA: cfg.ClientCA, Cert: cfg.Cert, Key: cfg.Key}); err != nil {
+		return err
+	}
+	for _, rule := range cfg.Ingress {
+		if rule.Hostname == "" || rule.Tunnel == "" {
+			return fmt.Errorf("ingress: both the hostname and the tunnel are required")
+		}
+		if _, err := uuid.Parse(rule.Tunnel); err != nil {
+			return fmt.Errorf("ingress %s: invalid tunnel ID %q", rule.Hostname, rule.Tunnel)
+		}
+	}
+	return nil
+}
+
+// serviceConfig returns the service of the tunnel handler listening on the transport.
+func serviceConfig(cfg *config.ServerConfig) (*xconfig.ServiceConfig, error) {
+	transport := config.Transport{Type: tunnel.DefaultTransport}
+	if cfg.Transport != nil && cfg.Transport.Type != "" {
+		transport = *cfg.Transport
+	}
+
+	tlsCfg := &xconfig.TLSConfig{
+		CertFile: cfg.Cert,
+		KeyFile:  cfg.Key,
+		CAFile:   cfg.ClientCA,
+	}
+	if cfg.Cert == "" {
+		var err error
+		if tlsCfg.CertFile, tlsCfg.KeyFile, err = selfSignedCert(); err != nil {
+			return nil, err
+		}
+	}
+
+	md := map[string]any{}
+	for k, v := range transport.Metadata {
+		md[k] = v
+	}
+	if transport.Path != "" {
+		md["path"] = transport.Path
+	}
+
+	handlerMd := map[string]any{
+		"ingress": name,
+		"sd":      name,
+	}
+	if cfg.Entrypoint != "" {
+		handlerMd["entrypoint"] = cfg.Entrypoint
+	}
+
+	return &xconfig.ServiceConfig{
+		Name: name,
+		Addr: cfg.Addr,
+		Handler: &xconfig.HandlerConfig{
+			Type:     "tunnel",
+			Observer: name,
+			Metadata: handlerMd,
+		},
+		Listener: &xconfig.ListenerConfig{
+			Type:     transport.Type,
+			TLS:      tlsCfg,
+			Metadata: md,
+		},
+	}, nil
+}
+
+// certFingerprint returns the fingerprint of the first certificate in the PEM file.
+func certFingerprint(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	for {
+		var block *pem.Block
+		if block, b = pem.Decode(b); block == nil {
+			return "", fmt.Errorf("%s: no certificate found", file)
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return "", err
+		}
+		return tunnel.Fingerprint(cert), nil
+	}
+}
+
+// selfSignedCert returns the self-signed certificate in the config directory, it is generated on first use.
+// The certificate is kept, so that the clients pinning it keep trusting the server.
+func selfSignedCert() (cert, key string, err error) {
+	cert = filepath.Join(config.Dir(), certFile)
+	key = filepath.Join(config.Dir(), keyFile)
+	if _, err := os.Stat(cert); err == nil {
+		return cert, key, nil
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return
+	}
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"GOST+"},
+			CommonName:   "gost.plus",
+		},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		return
+	}
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return
+	}
+
+	if err = os.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		return
+	}
+	err = os.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	return
+}
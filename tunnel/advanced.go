@@ -0,0 +1,561 @@
+package tunnel
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/observer/stats"
+	"github.com/go-gost/core/service"
+	cfg "github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/parsing"
+	admission_parser "github.com/go-gost/x/config/parsing/admission"
+	auth_parser "github.com/go-gost/x/config/parsing/auth"
+	bypass_parser "github.com/go-gost/x/config/parsing/bypass"
+	chain_parser "github.com/go-gost/x/config/parsing/chain"
+	hop_parser "github.com/go-gost/x/config/parsing/hop"
+	hosts_parser "github.com/go-gost/x/config/parsing/hosts"
+	limiter_parser "github.com/go-gost/x/config/parsing/limiter"
+	resolver_parser "github.com/go-gost/x/config/parsing/resolver"
+	service_parser "github.com/go-gost/x/config/parsing/service"
+	"github.com/go-gost/x/registry"
+	xservice "github.com/go-gost/x/service"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ServerChain is the chain name which refers to the tunnel server in the config of an advanced tunnel.
+	ServerChain = "tunnel"
+)
+
+var (
+	ErrNoService  = errors.New("no service defined")
+	ErrHostAccess = errors.New("commands, network namespaces, interfaces and socket options are not allowed")
+)
+
+var (
+	// hostMetadata are the metadata keys which run shell commands or change the network setup of the host,
+	// they are rejected since the config of an advanced tunnel may come from an imported bundle.
+	hostMetadata = []string{
+		parsing.MDKeyPreUp, parsing.MDKeyPreDown, parsing.MDKeyPostUp, parsing.MDKeyPostDown,
+		parsing.MDKeyNetns, parsing.MDKeyNetnsOut, parsing.MDKeyInterface, parsing.MDKeySoMark,
+	}
+	// hostTypes are the listener and handler types which create network interfaces and routes on the host.
+	hostTypes = []string{"tun", "tap"}
+)
+
+// ParseAdvancedConfig decodes the gost config of an advanced tunnel, the same way as gost reads its config file.
+func ParseAdvancedConfig(s string) (*config.Config, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(s)); err != nil {
+		return nil, err
+	}
+
+	c := &config.Config{}
+	if err := v.Unmarshal(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ValidateAdvancedConfig checks the gost config of an advanced tunnel without running it,
+// the services are only checked for the required fields, since parsing a service starts listening.
+func ValidateAdvancedConfig(s string) error {
+	c, err := ParseAdvancedConfig(s)
+	if err != nil {
+		return err
+	}
+	if len(c.Services) == 0 {
+		return ErrNoService
+	}
+
+	names := map[string]bool{}
+	for _, svc := range c.Services {
+		if svc == nil {
+			continue
+		}
+		if svc.Name == "" {
+			return errors.New("service name is required")
+		}
+		if names[svc.Name] {
+			return fmt.Errorf("service %s: duplicate name", svc.Name)
+		}
+		names[svc.Name] = true
+
+		if svc.Handler == nil || svc.Handler.Type == "" {
+			return fmt.Errorf("service %s: handler type is required", svc.Name)
+		}
+		if svc.Listener == nil || svc.Listener.Type == "" {
+			return fmt.Errorf("service %s: listener type is required", svc.Name)
+		}
+		if !registry.HandlerRegistry().IsRegistered(svc.Handler.Type) {
+			return fmt.Errorf("service %s: unknown handler %s", svc.Name, svc.Handler.Type)
+		}
+		if !registry.ListenerRegistry().IsRegistered(svc.Listener.Type) {
+			return fmt.Errorf("service %s: unknown listener %s", svc.Name, svc.Listener.Type)
+		}
+		for _, v := range []string{svc.Handler.Type, svc.Listener.Type} {
+			if slices.Contains(hostTypes, strings.ToLower(v)) {
+				return fmt.Errorf("service %s: %s is not allowed", svc.Name, v)
+			}
+		}
+		if svc.Interface != "" || svc.SockOpts != nil || hasHostMetadata(svc.Metadata) {
+			return fmt.Errorf("service %s: %w", svc.Name, ErrHostAccess)
+		}
+	}
+
+	for _, hop := range c.Hops {
+		if err := validateHop(hop); err != nil {
+			return err
+		}
+	}
+	for _, chainCfg := range c.Chains {
+		if chainCfg == nil {
+			continue
+		}
+		if chainCfg.Name == ServerChain {
+			return fmt.Errorf("chain name %s is reserved", ServerChain)
+		}
+		for _, hop := range chainCfg.Hops {
+			if err := validateHop(hop); err != nil {
+				return fmt.Errorf("chain %s: %w", chainCfg.Name, err)
+			}
+		}
+		if _, err := chain_parser.ParseChain(chainCfg, logger.Default()); err != nil {
+			return fmt.Errorf("chain %s: %w", chainCfg.Name, err)
+		}
+	}
+	for _, resolverCfg := range c.Resolvers {
+		if _, err := resolver_parser.ParseResolver(resolverCfg); err != nil {
+			return fmt.Errorf("resolver %s: %w", resolverCfg.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateHop rejects the hop and the nodes which change the network setup of the host.
+func validateHop(hop *config.HopConfig) error {
+	if hop == nil {
+		return nil
+	}
+	if hop.Interface != "" || hop.SockOpts != nil || hasHostMetadata(hop.Metadata) {
+		return fmt.Errorf("hop %s: %w", hop.Name, ErrHostAccess)
+	}
+	for _, node := range hop.Nodes {
+		if node == nil {
+			continue
+		}
+		if node.Interface != "" || node.Netns != "" || node.SockOpts != nil || hasHostMetadata(node.Metadata) {
+			return fmt.Errorf("hop %s: node %s: %w", hop.Name, node.Name, ErrHostAccess)
+		}
+	}
+	return nil
+}
+
+// hasHostMetadata reports whether the metadata has any of the hostMetadata keys, the keys are case-insensitive as in gost.
+func hasHostMetadata(md map[string]any) bool {
+	for k := range md {
+		for _, v := range hostMetadata {
+			if strings.EqualFold(k, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type advancedTunnel struct {
+	endpoint string
+	opts     Options
+	config   *config.Config
+	services []service.Service
+	// unregister removes the components registered by the tunnel from the gost registries.
+	unregister []func()
+	favorite   atomic.Bool
+	stats      cfg.ServiceStats
+
+	cclose chan struct{}
+
+	err error
+	mu  sync.RWMutex
+}
+
+// NewAdvancedTunnel creates a tunnel running the services of a raw gost config, see Options.Config.
+// The chain named ServerChain in the config connects to the tunnel server.
+func NewAdvancedTunnel(opts ...Option) Tunnel {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.ID == "" {
+		options.ID = uuid.NewString()
+	}
+
+	v := md5.Sum([]byte(options.ID))
+	endpoint := hex.EncodeToString(v[:8])
+
+	if options.Name == "" {
+		options.Name = endpoint
+	}
+	if options.CreatedAt.IsZero() {
+		options.CreatedAt = time.Now()
+	}
+
+	s := &advancedTunnel{
+		endpoint: endpoint,
+		opts:     options,
+		cclose:   make(chan struct{}),
+	}
+
+	return s
+}
+
+func (s *advancedTunnel) ID() string {
+	return s.opts.ID
+}
+
+func (s *advancedTunnel) Type() string {
+	return AdvancedTunnel
+}
+
+func (s *advancedTunnel) Name() string {
+	return s.opts.Name
+}
+
+// Endpoint returns the names of the services.
+func (s *advancedTunnel) Endpoint() string {
+	c, err := ParseAdvancedConfig(s.opts.Config)
+	if err != nil {
+		return ""
+	}
+
+	var names []string
+	for _, svc := range c.Services {
+		if svc != nil {
+			names = append(names, svc.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func (s *advancedTunnel) Entrypoint() string {
+	return fmt.Sprintf("%s.%s", s.endpoint, EndpointAddr())
+}
+
+func (s *advancedTunnel) Options() Options {
+	return s.opts
+}
+
+func (s *advancedTunnel) Favorite(b bool) {
+	s.favorite.Store(b)
+}
+
+func (s *advancedTunnel) IsFavorite() bool {
+	return s.favorite.Load()
+}
+
+func (s *advancedTunnel) init() error {
+	if err := ValidateAdvancedConfig(s.opts.Config); err != nil {
+		return err
+	}
+
+	c, err := ParseAdvancedConfig(s.opts.Config)
+	if err != nil {
+		return err
+	}
+
+	// the server chain is registered with the unique tunnel ID.
+	chainName := s.chainName()
+	for _, svc := range c.Services {
+		if svc == nil {
+			continue
+		}
+		if svc.Handler.Chain == ServerChain {
+			svc.Handler.Chain = chainName
+		}
+		if svc.Listener.Chain == ServerChain {
+			svc.Listener.Chain = chainName
+		}
+		// the stats are required by the stats collection of the tunnels.
+		if svc.Metadata == nil {
+			svc.Metadata = make(map[string]any)
+		}
+		svc.Metadata["enableStats"] = true
+	}
+	c.Chains = append(c.Chains, ChainConfig(s.opts.ID, chainName, s.opts.Transport, s.opts.Proxies))
+
+	s.config = c
+	return nil
+}
+
+func (s *advancedTunnel) chainName() string {
+	return "gost.plus/" + s.opts.ID
+}
+
+func (s *advancedTunnel) Run() (err error) {
+	if s.IsClosed() {
+		return ErrTunnelClosed
+	}
+
+	defer func() {
+		s.setErr(err)
+	}()
+
+	if err = s.init(); err != nil {
+		return
+	}
+
+	if err = s.register(); err != nil {
+		s.close()
+		return
+	}
+
+	var services []service.Service
+	for _, svc := range s.config.Services {
+		if svc == nil {
+			continue
+		}
+
+		var srv service.Service
+		srv, err = service_parser.ParseService(svc)
+		if err != nil {
+			err = fmt.Errorf("service %s: %w", svc.Name, err)
+			for _, srv := range services {
+				srv.Close()
+			}
+			s.close()
+			return
+		}
+		services = append(services, srv)
+	}
+
+	s.mu.Lock()
+	s.services = services
+	s.mu.Unlock()
+
+	for _, srv := range services {
+		go func(srv service.Service) {
+			if err := srv.Serve(); err != nil && !s.IsClosed() {
+				s.setErr(err)
+			}
+		}(srv)
+	}
+
+	return nil
+}
+
+// register adds the named components of the config to the gost registries, so that the services can refer to them.
+// The names are global, they must not be used by another advanced tunnel.
+func (s *advancedTunnel) register() error {
+	log := logger.Default().WithFields(map[string]any{
+		"kind":    "service",
+		"service": s.opts.Name,
+	})
+	c := s.config
+
+	for _, v := range c.Authers {
+		if err := add(s, registry.AutherRegistry(), v.Name, auth_parser.ParseAuther(v)); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.Admissions {
+		if err := add(s, registry.AdmissionRegistry(), v.Name, admission_parser.ParseAdmission(v)); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.Bypasses {
+		if err := add(s, registry.BypassRegistry(), v.Name, bypass_parser.ParseBypass(v)); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.Resolvers {
+		r, err := resolver_parser.ParseResolver(v)
+		if err != nil {
+			return err
+		}
+		if err := add(s, registry.ResolverRegistry(), v.Name, r); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.Hosts {
+		if err := add(s, registry.HostsRegistry(), v.Name, hosts_parser.ParseHostMapper(v)); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.Limiters {
+		if err := add(s, registry.TrafficLimiterRegistry(), v.Name, limiter_parser.ParseTrafficLimiter(v)); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.CLimiters {
+		if err := add(s, registry.ConnLimiterRegistry(), v.Name, limiter_parser.ParseConnLimiter(v)); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.RLimiters {
+		if err := add(s, registry.RateLimiterRegistry(), v.Name, limiter_parser.ParseRateLimiter(v)); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.Hops {
+		h, err := hop_parser.ParseHop(v, log)
+		if err != nil {
+			return err
+		}
+		if err := add(s, registry.HopRegistry(), v.Name, h); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.Chains {
+		ch, err := chain_parser.ParseChain(v, log)
+		if err != nil {
+			return err
+		}
+		if err := add(s, registry.ChainRegistry(), v.Name, ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type registrar[T any] interface {
+	Register(name string, v T) error
+	Unregister(name string)
+}
+
+func add[T any](s *advancedTunnel, r registrar[T], name string, v T) error {
+	if name == "" {
+		return nil
+	}
+	if err := r.Register(name, v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	s.unregister = append(s.unregister, func() { r.Unregister(name) })
+	return nil
+}
+
+func (s *advancedTunnel) Status() *xservice.Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.services) == 0 {
+		return nil
+	}
+	if ss, _ := s.services[0].(ServiceStatus); ss != nil {
+		return ss.Status()
+	}
+	return nil
+}
+
+// ServiceStats sums up the stats of all the services.
+func (s *advancedTunnel) ServiceStats() stats.Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var group statsGroup
+	for _, srv := range s.services {
+		if ss, _ := srv.(ServiceStatus); ss != nil {
+			if st := ss.Status().Stats(); st != nil {
+				group = append(group, st)
+			}
+		}
+	}
+	return group
+}
+
+func (s *advancedTunnel) Stats() cfg.ServiceStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats
+}
+
+func (s *advancedTunnel) SetStats(stats cfg.ServiceStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = stats
+}
+
+func (s *advancedTunnel) Close() error {
+	defer func() {
+		select {
+		case <-s.cclose:
+		default:
+			close(s.cclose)
+		}
+	}()
+
+	return s.close()
+}
+
+// close stops the services and removes the registered components.
+func (s *advancedTunnel) close() error {
+	s.mu.Lock()
+	services, unregister := s.services, s.unregister
+	s.services, s.unregister = nil, nil
+	s.mu.Unlock()
+
+	var errs []error
+	for _, srv := range services {
+		errs = append(errs, srv.Close())
+	}
+	for _, f := range unregister {
+		f()
+	}
+	return errors.Join(errs...)
+}
+
+func (s *advancedTunnel) IsClosed() bool {
+	select {
+	case <-s.cclose:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *advancedTunnel) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *advancedTunnel) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// statsGroup is the sum of the stats of multiple services.
+type statsGroup []stats.Stats
+
+func (g statsGroup) Add(kind stats.Kind, n int64) {}
+
+func (g statsGroup) Get(kind stats.Kind) (n uint64) {
+	for _, s := range g {
+		n += s.Get(kind)
+	}
+	return
+}
+
+func (g statsGroup) Reset() {}
+
+func (g statsGroup) IsUpdated() bool {
+	for _, s := range g {
+		if s.IsUpdated() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/x/config"
+)
+
+// The strategies of selecting the backend of a tunnel with multiple endpoints.
+const (
+	StrategyRoundRobin = "round"
+	StrategyRandom     = "random"
+	// StrategyFailover always selects the first available backend.
+	StrategyFailover = "failover"
+)
+
+const (
+	// BackendFailTimeout is the duration a failed backend is excluded from the selection.
+	BackendFailTimeout = 30 * time.Second
+)
+
+// ValidateStrategy checks the backend selection strategy, the empty one is the default StrategyRoundRobin.
+func ValidateStrategy(strategy string) error {
+	switch strategy {
+	case "", StrategyRoundRobin, StrategyRandom, StrategyFailover:
+		return nil
+	default:
+		return fmt.Errorf("unknown strategy %s", strategy)
+	}
+}
+
+// Endpoints splits the comma-separated endpoints of a tunnel, the duplicated ones are removed.
+func Endpoints(endpoint string) []string {
+	var endpoints []string
+	for _, s := range strings.Split(endpoint, ",") {
+		if s = strings.TrimSpace(s); s != "" && !slices.Contains(endpoints, s) {
+			endpoints = append(endpoints, s)
+		}
+	}
+	return endpoints
+}
+
+// selectorConfig returns the selector config of the forwarder for the strategy, it is nil for a single endpoint.
+// A failed backend is skipped for BackendFailTimeout, or until it passes the health check.
+func selectorConfig(strategy string, endpoints int) *config.SelectorConfig {
+	if endpoints <= 1 {
+		return nil
+	}
+
+	cfg := &config.SelectorConfig{
+		Strategy:    "round",
+		MaxFails:    1,
+		FailTimeout: BackendFailTimeout,
+	}
+	switch strategy {
+	case StrategyRandom:
+		cfg.Strategy = "rand"
+	case StrategyFailover:
+		cfg.Strategy = "fifo"
+	}
+	return cfg
+}
+
+// BackendStats is the stats of a local endpoint of a tunnel.
+type BackendStats struct {
+	Addr string
+	// Down reports whether the backend is excluded from the selection after a failure.
+	Down         bool
+	CurrentConns uint64
+	TotalConns   uint64
+	TotalErrs    uint64
+	InputBytes   uint64
+	OutputBytes  uint64
+}
+
+// MultiBackend is implemented by the tunnels forwarding to multiple endpoints.
+type MultiBackend interface {
+	// Backends returns the stats of the backends, it is nil if the tunnel is not running.
+	Backends() []BackendStats
+}
+
+// backendChecker is implemented by the tunnels whose backends are marked by the health check.
+type backendChecker interface {
+	checkBackends(ctx context.Context) error
+}
+
+type backend struct {
+	node         *chain.Node
+	currentConns atomic.Int64
+	totalConns   atomic.Uint64
+	totalErrs    atomic.Uint64
+	inputBytes   atomic.Uint64
+	outputBytes  atomic.Uint64
+}
+
+func (b *backend) isDown() bool {
+	marker := b.node.Marker()
+	return marker != nil && marker.Count() > 0 && time.Since(marker.Time()) < BackendFailTimeout
+}
+
+// backendGroup tracks the backends of a forwarder.
+type backendGroup struct {
+	backends []*backend
+}
+
+func newBackendGroup(nodes []*chain.Node) *backendGroup {
+	g := &backendGroup{}
+	for _, node := range nodes {
+		g.backends = append(g.backends, &backend{node: node})
+	}
+	return g
+}
+
+func (g *backendGroup) get(addr string) *backend {
+	if g == nil {
+		return nil
+	}
+	for _, b := range g.backends {
+		if b.node.Addr == addr {
+			return b
+		}
+	}
+	return nil
+}
+
+// Router wraps the router of the forwarding handler to count the connections to each backend.
+func (g *backendGroup) Router(r chain.Router) chain.Router {
+	return &backendRouter{
+		Router: r,
+		group:  g,
+	}
+}
+
+func (g *backendGroup) Stats() []BackendStats {
+	if g == nil {
+		return nil
+	}
+
+	var stats []BackendStats
+	for _, b := range g.backends {
+		stats = append(stats, BackendStats{
+			Addr:         b.node.Addr,
+			Down:         b.isDown(),
+			CurrentConns: uint64(max(b.currentConns.Load(), 0)),
+			TotalConns:   b.totalConns.Load(),
+			TotalErrs:    b.totalErrs.Load(),
+			InputBytes:   b.inputBytes.Load(),
+			OutputBytes:  b.outputBytes.Load(),
+		})
+	}
+	return stats
+}
+
+// Check probes the backends and marks the unreachable ones as failed, so that they are skipped by the selector.
+func (g *backendGroup) Check(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+
+	errs := make([]error, len(g.backends))
+
+	var wg sync.WaitGroup
+	for i, b := range g.backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", b.node.Addr)
+			marker := b.node.Marker()
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", b.node.Addr, err)
+				if marker != nil {
+					marker.Mark()
+				}
+				return
+			}
+			conn.Close()
+			if marker != nil {
+				marker.Reset()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+type backendRouter struct {
+	chain.Router
+	group *backendGroup
+}
+
+func (r *backendRouter) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := r.Router.Dial(ctx, network, address)
+
+	b := r.group.get(address)
+	if b == nil {
+		return conn, err
+	}
+
+	b.totalConns.Add(1)
+	if err != nil {
+		b.totalErrs.Add(1)
+		return nil, err
+	}
+	b.currentConns.Add(1)
+	return &backendConn{Conn: conn, backend: b}, nil
+}
+
+type backendConn struct {
+	net.Conn
+	backend *backend
+	once    sync.Once
+}
+
+func (c *backendConn) Read(b []byte) (n int, err error) {
+	n, err = c.Conn.Read(b)
+	c.backend.outputBytes.Add(uint64(n))
+	return
+}
+
+func (c *backendConn) Write(b []byte) (n int, err error) {
+	n, err = c.Conn.Write(b)
+	c.backend.inputBytes.Add(uint64(n))
+	return
+}
+
+func (c *backendConn) Close() error {
+	c.once.Do(func() {
+		c.backend.currentConns.Add(-1)
+	})
+	return c.Conn.Close()
+}
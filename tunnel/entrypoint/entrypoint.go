@@ -7,11 +7,14 @@ import (
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/gost.plus/config"
 	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/history"
 )
 
 const (
-	TCPEntryPoint = "tcp"
-	UDPEntryPoint = "udp"
+	TCPEntryPoint  = "tcp"
+	UDPEntryPoint  = "udp"
+	HTTPEntryPoint = "http"
+	FileEntryPoint = "file"
 )
 
 var (
@@ -91,6 +94,7 @@ func Delete(id string) {
 		if s != nil && s.ID() == id {
 			s.Close()
 			entryPoints.list[i] = nil
+			history.EntryPoints.Delete(id)
 			return
 		}
 	}
@@ -102,19 +106,9 @@ func LoadConfig() {
 			continue
 		}
 
-		ep := createEntryPoint(cfg.Type, tunnel.Options{
-			ID:        cfg.ID,
-			Name:      cfg.Name,
-			Endpoint:  cfg.Endpoint,
-			Hostname:  cfg.Hostname,
-			Username:  cfg.Username,
-			Password:  cfg.Password,
-			EnableTLS: cfg.EnableTLS,
-			Keepalive: cfg.Keepalive,
-			TTL:       cfg.TTL,
-			CreatedAt: cfg.CreatedAt,
-			Stats:     cfg.Stats,
-		})
+		opts := tunnel.OptionsOf(cfg)
+		opts.Stats = config.EntryPointStats(cfg.ID)
+		ep := NewEntryPoint(cfg.Type, opts)
 		if ep == nil {
 			continue
 		}
@@ -140,22 +134,10 @@ func SaveConfig() error {
 			continue
 		}
 
-		opts := ep.Options()
-
-		cfg.EntryPoints = append(cfg.EntryPoints, &config.Tunnel{
-			ID:        ep.ID(),
-			Name:      ep.Name(),
-			Type:      ep.Type(),
-			Endpoint:  ep.Entrypoint(),
-			Hostname:  opts.Hostname,
-			Username:  opts.Username,
-			Password:  opts.Password,
-			EnableTLS: opts.EnableTLS,
-			Favorite:  ep.IsFavorite(),
-			Closed:    ep.IsClosed(),
-			CreatedAt: opts.CreatedAt,
-			Stats:     ep.Stats(),
-		})
+		c := ConfigOf(ep)
+		c.Favorite = ep.IsFavorite()
+		c.Closed = ep.IsClosed()
+		cfg.EntryPoints = append(cfg.EntryPoints, c)
 	}
 
 	config.Set(cfg)
@@ -167,7 +149,95 @@ func SaveConfig() error {
 	return nil
 }
 
-func createEntryPoint(st string, opts tunnel.Options) (ep EntryPoint) {
+// ConfigOf returns the config of the entrypoint, the endpoint in the config is the listen address of the entrypoint.
+func ConfigOf(ep EntryPoint) *config.Tunnel {
+	c := tunnel.ConfigOf(ep)
+	c.Endpoint = ep.Entrypoint()
+	return c
+}
+
+// ValidateOptions checks the options of an entrypoint, the connection to the tunnel server is validated,
+// and the listen address of a file entrypoint, see NewFileEntryPoint.
+func ValidateOptions(st string, opts tunnel.Options) error {
+	if st == FileEntryPoint && opts.Endpoint != "" {
+		if err := tunnel.ValidateLoopback(opts.Endpoint); err != nil {
+			return err
+		}
+	}
+	if err := tunnel.ValidateTransport(opts.Transport); err != nil {
+		return err
+	}
+	return tunnel.ValidateUpstream(opts.Transport, opts.Proxies)
+}
+
+// SaveStats persists the stats separately from the config, see config.StatsStore.
+func SaveStats() error {
+	stats := make(map[string]config.ServiceStats)
+	for i := 0; i < Count(); i++ {
+		if ep := GetIndex(i); ep != nil {
+			stats[ep.ID()] = ep.Stats()
+		}
+	}
+	config.SetEntryPointStats(stats)
+
+	if err := config.SaveStats(); err != nil {
+		logger.Default().Error(err)
+		return err
+	}
+	return nil
+}
+
+// Reload restarts all the running entrypoints, so that the changed settings take effect.
+func Reload() {
+	for i := 0; i < Count(); i++ {
+		ep := GetIndex(i)
+		if ep == nil || ep.IsClosed() {
+			continue
+		}
+		if _, err := restart(ep); err != nil {
+			logger.Default().Error(err)
+		}
+	}
+}
+
+var (
+	supervisor = tunnel.NewSupervisor()
+)
+
+// Supervise restarts the failed entrypoints, it is called periodically by the runner.
+func Supervise() {
+	for i := 0; i < Count(); i++ {
+		supervisor.Check(GetIndex(i), restart)
+	}
+}
+
+// Retry returns the retry state of the entrypoint, see tunnel.Supervisor.State.
+func Retry(id string) (tunnel.RetryState, bool) {
+	return supervisor.State(id)
+}
+
+// Restarts returns the total number of restarts of the entrypoint, see tunnel.Supervisor.Restarts.
+func Restarts(id string) int {
+	return supervisor.Restarts(id)
+}
+
+// restart replaces the entrypoint with a new one created from the same options and runs it.
+func restart(ep EntryPoint) (EntryPoint, error) {
+	ep.Close()
+
+	opts := ep.Options()
+	opts.Stats = ep.Stats()
+	e := NewEntryPoint(ep.Type(), opts)
+	if e == nil {
+		return nil, nil
+	}
+	Set(e)
+
+	return e, e.Run()
+}
+
+// NewEntryPoint creates an entrypoint of the given type, nil is returned for an unknown type.
+func NewEntryPoint(st string, opts tunnel.Options) (ep EntryPoint) {
 	options := []tunnel.Option{
 		tunnel.IDOption(opts.ID),
 		tunnel.NameOption(opts.Name),
@@ -176,6 +246,10 @@ func createEntryPoint(st string, opts tunnel.Options) (ep EntryPoint) {
 		tunnel.UsernameOption(opts.Username),
 		tunnel.PasswordOption(opts.Password),
 		tunnel.EnableTLSOption(opts.EnableTLS),
+		tunnel.KeepaliveOption(opts.Keepalive),
+		tunnel.TTLOption(opts.TTL),
+		tunnel.TransportOption(opts.Transport),
+		tunnel.ProxiesOption(opts.Proxies),
 		tunnel.CreatedAtOption(opts.CreatedAt),
 	}
 	switch st {
@@ -183,6 +257,10 @@ func createEntryPoint(st string, opts tunnel.Options) (ep EntryPoint) {
 		ep = NewTCPEntryPoint(options...)
 	case UDPEntryPoint:
 		ep = NewUDPEntryPoint(options...)
+	case HTTPEntryPoint:
+		ep = NewHTTPEntryPoint(options...)
+	case FileEntryPoint:
+		ep = NewFileEntryPoint(options...)
 	default:
 		return nil
 	}
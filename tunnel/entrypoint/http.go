@@ -0,0 +1,62 @@
+package entrypoint
+
+import (
+	"encoding/base64"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/gost.plus/tunnel"
+	xauth "github.com/go-gost/x/auth"
+	"github.com/go-gost/x/config"
+)
+
+// NewHTTPEntryPoint creates a local HTTP reverse proxy to an HTTP tunnel,
+// the Host header is rewritten to the hostname and the clients must authenticate with the username and password.
+func NewHTTPEntryPoint(opts ...tunnel.Option) EntryPoint {
+	return newTCPEntryPoint(HTTPEntryPoint, httpNode, opts...)
+}
+
+// NewFileEntryPoint creates a local HTTP reverse proxy to a file tunnel,
+// the username and password are the credentials of the file tunnel, they are sent on behalf of the clients,
+// so that the files can be browsed and downloaded from the entrypoint in a browser directly.
+// For this reason the file entrypoint only listens on a loopback address.
+func NewFileEntryPoint(opts ...tunnel.Option) EntryPoint {
+	return newTCPEntryPoint(FileEntryPoint, fileNode, opts...)
+}
+
+func httpNode(opts tunnel.Options) (*config.HTTPNodeConfig, error) {
+	node := &config.HTTPNodeConfig{
+		Host: opts.Hostname,
+	}
+	if opts.Username != "" {
+		node.Auth = &config.AuthConfig{
+			Username: opts.Username,
+			Password: opts.Password,
+		}
+	}
+	return node, nil
+}
+
+func fileNode(opts tunnel.Options) (*config.HTTPNodeConfig, error) {
+	if err := tunnel.ValidateLoopback(opts.Endpoint); err != nil {
+		return nil, err
+	}
+
+	node := &config.HTTPNodeConfig{}
+	if opts.Username != "" {
+		node.RequestHeader = map[string]string{
+			"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(opts.Username+":"+opts.Password)),
+		}
+	}
+	return node, nil
+}
+
+func httpNodeSettings(node *config.HTTPNodeConfig) *chain.HTTPNodeSettings {
+	settings := &chain.HTTPNodeSettings{
+		Host:          node.Host,
+		RequestHeader: node.RequestHeader,
+	}
+	if node.Auth != nil {
+		settings.Auther = xauth.NewAuthenticator(xauth.AuthsOption(map[string]string{node.Auth.Username: node.Auth.Password}))
+	}
+	return settings
+}
@@ -27,7 +27,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// tcpEntryPoint forwards the local connections to a tunnel. With an httpNode it is a local HTTP reverse proxy
+// to an HTTP or file tunnel, see NewHTTPEntryPoint and NewFileEntryPoint.
 type tcpEntryPoint struct {
+	typ string
+	// httpNode returns the HTTP settings of the node to the tunnel, it is nil for the TCP entrypoint.
+	httpNode func(opts tunnel.Options) (*config.HTTPNodeConfig, error)
 	endpoint string
 	opts     tunnel.Options
 	config   *config.Config
@@ -42,6 +47,10 @@ type tcpEntryPoint struct {
 }
 
 func NewTCPEntryPoint(opts ...tunnel.Option) EntryPoint {
+	return newTCPEntryPoint(TCPEntryPoint, nil, opts...)
+}
+
+func newTCPEntryPoint(typ string, httpNode func(opts tunnel.Options) (*config.HTTPNodeConfig, error), opts ...tunnel.Option) EntryPoint {
 	var options tunnel.Options
 	for _, opt := range opts {
 		opt(&options)
@@ -66,6 +75,8 @@ func NewTCPEntryPoint(opts ...tunnel.Option) EntryPoint {
 	}
 
 	s := &tcpEntryPoint{
+		typ:      typ,
+		httpNode: httpNode,
 		endpoint: endpoint,
 		opts:     options,
 		cclose:   make(chan struct{}),
@@ -79,7 +90,7 @@ func (s *tcpEntryPoint) ID() string {
 }
 
 func (s *tcpEntryPoint) Type() string {
-	return TCPEntryPoint
+	return s.typ
 }
 
 func (s *tcpEntryPoint) Name() string {
@@ -87,7 +98,7 @@ func (s *tcpEntryPoint) Name() string {
 }
 
 func (s *tcpEntryPoint) Endpoint() string {
-	return fmt.Sprintf("%s.%s", s.endpoint, tunnel.EndpointAddr)
+	return fmt.Sprintf("%s.%s", s.endpoint, tunnel.EndpointAddr())
 }
 
 func (s *tcpEntryPoint) Entrypoint() string {
@@ -107,29 +118,39 @@ func (s *tcpEntryPoint) IsFavorite() bool {
 }
 
 func (s *tcpEntryPoint) init() error {
+	node := &config.ForwardNodeConfig{
+		Name: s.opts.Name,
+		Addr: s.Endpoint(),
+	}
+	var md map[string]any
+	if s.httpNode != nil {
+		v, err := s.httpNode(s.opts)
+		if err != nil {
+			return err
+		}
+		node.HTTP = v
+		md = map[string]any{"sniffing": true}
+	}
+
 	tcp := &config.ServiceConfig{
 		Name: s.opts.Name,
 		Addr: s.opts.Endpoint,
 		Handler: &config.HandlerConfig{
-			Type:  "tcp",
-			Chain: s.opts.Name,
+			Type:     "tcp",
+			Chain:    s.opts.Name,
+			Metadata: md,
 		},
 		Listener: &config.ListenerConfig{
 			Type: "tcp",
 		},
 		Forwarder: &config.ForwarderConfig{
-			Nodes: []*config.ForwardNodeConfig{
-				{
-					Name: s.opts.Name,
-					Addr: s.Endpoint(),
-				},
-			},
+			Nodes: []*config.ForwardNodeConfig{node},
 		},
 	}
 
 	s.config = &config.Config{
 		Services: []*config.ServiceConfig{tcp},
-		Chains:   []*config.ChainConfig{tunnel.ChainConfig(s.opts.ID, s.opts.Name)},
+		Chains:   []*config.ChainConfig{tunnel.ChainConfig(s.opts.ID, s.opts.Name, s.opts.Transport, s.opts.Proxies)},
 	}
 	return nil
 }
@@ -185,9 +206,13 @@ func (s *tcpEntryPoint) Run() (err error) {
 		}
 
 		node := cfg.Forwarder.Nodes[0]
+		var nodeOpts []chain.NodeOption
+		if node.HTTP != nil {
+			nodeOpts = append(nodeOpts, chain.HTTPNodeOption(httpNodeSettings(node.HTTP)))
+		}
 		if forwarder, ok := h.(handler.Forwarder); ok {
 			forwarder.Forward(hop.NewHop(
-				hop.NodeOption(chain.NewNode(node.Name, node.Addr)),
+				hop.NodeOption(chain.NewNode(node.Name, node.Addr, nodeOpts...)),
 				hop.LoggerOption(log.WithFields(map[string]any{"kind": "hop"})),
 			))
 		}
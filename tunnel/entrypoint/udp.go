@@ -87,7 +87,7 @@ func (s *udpEntryPoint) Name() string {
 }
 
 func (s *udpEntryPoint) Endpoint() string {
-	return fmt.Sprintf("%s.%s", s.endpoint, tunnel.EndpointAddr)
+	return fmt.Sprintf("%s.%s", s.endpoint, tunnel.EndpointAddr())
 }
 
 func (s *udpEntryPoint) Entrypoint() string {
@@ -133,7 +133,7 @@ func (s *udpEntryPoint) init() error {
 
 	s.config = &config.Config{
 		Services: []*config.ServiceConfig{tcp},
-		Chains:   []*config.ChainConfig{tunnel.ChainConfig(s.opts.ID, s.opts.Name)},
+		Chains:   []*config.ChainConfig{tunnel.ChainConfig(s.opts.ID, s.opts.Name, s.opts.Transport, s.opts.Proxies)},
 	}
 	return nil
 }
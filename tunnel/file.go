@@ -96,7 +96,7 @@ func (s *fileTunnel) Endpoint() string {
 }
 
 func (s *fileTunnel) Entrypoint() string {
-	return fmt.Sprintf("https://%s.%s", s.endpoint, EndpointAddr)
+	return fmt.Sprintf("https://%s.%s", s.endpoint, EndpointAddr())
 }
 
 func (s *fileTunnel) Options() Options {
@@ -144,7 +144,7 @@ func (s *fileTunnel) init() error {
 
 	s.config = &config.Config{
 		Services: []*config.ServiceConfig{file, rtcp},
-		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name)},
+		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name, s.opts.Transport, s.opts.Proxies)},
 	}
 
 	return nil
@@ -0,0 +1,165 @@
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-gost/core/handler"
+	cfg "github.com/go-gost/gost.plus/config"
+)
+
+// ValidateHeaders checks the header rules of an HTTP tunnel.
+func ValidateHeaders(headers []cfg.HTTPHeader) error {
+	for _, header := range headers {
+		if !validHeaderName(header.Name) {
+			return fmt.Errorf("invalid header name %q", header.Name)
+		}
+		if strings.ContainsAny(header.Value, "\r\n") {
+			return fmt.Errorf("invalid value of header %s", header.Name)
+		}
+	}
+	return nil
+}
+
+func validHeaderName(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return r <= ' ' || r >= 0x7f || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r)
+	}) < 0
+}
+
+// setHeaders returns the headers to be set by the HTTP settings of the forwarding nodes.
+func setHeaders(headers []cfg.HTTPHeader) map[string]string {
+	var m map[string]string
+	for _, header := range headers {
+		if header.Remove {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[header.Name] = header.Value
+	}
+	return m
+}
+
+// removeHeaders returns the names of the headers to be removed.
+func removeHeaders(headers []cfg.HTTPHeader) []string {
+	var names []string
+	for _, header := range headers {
+		if header.Remove {
+			names = append(names, header.Name)
+		}
+	}
+	return names
+}
+
+// headerHandler removes the headers of the HTTP requests and responses passing through the handler.
+// The forwarding nodes can only set the headers, so the requests are parsed once more before the handler,
+// the other traffic is passed to the handler untouched.
+type headerHandler struct {
+	handler.Handler
+	request  []string
+	response []string
+}
+
+func (h *headerHandler) Handle(ctx context.Context, conn net.Conn, opts ...handler.HandleOption) error {
+	br := bufio.NewReader(conn)
+	if !isHTTPRequest(br) {
+		return h.Handler.Handle(ctx, &bufferedConn{Conn: conn, r: br}, opts...)
+	}
+
+	c1, c2 := net.Pipe()
+	go h.serve(conn, br, c2)
+
+	return h.Handler.Handle(ctx, &pipeConn{Conn: c1, local: conn.LocalAddr(), remote: conn.RemoteAddr()}, opts...)
+}
+
+// serve relays the requests from the client conn to the handler through the pipe, and the responses back.
+func (h *headerHandler) serve(conn net.Conn, br *bufio.Reader, pc net.Conn) {
+	defer conn.Close()
+	defer pc.Close()
+
+	pbr := bufio.NewReader(pc)
+	for {
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		for _, name := range h.request {
+			req.Header.Del(name)
+		}
+		// prevent the default User-Agent from being added by req.Write.
+		if _, ok := req.Header["User-Agent"]; !ok {
+			req.Header["User-Agent"] = []string{""}
+		}
+		if err := req.Write(pc); err != nil {
+			return
+		}
+
+		resp, err := http.ReadResponse(pbr, req)
+		if err != nil {
+			return
+		}
+		for _, name := range h.response {
+			resp.Header.Del(name)
+		}
+		err = resp.Write(conn)
+		resp.Body.Close()
+		if err != nil {
+			return
+		}
+
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			go io.Copy(pc, br)
+			io.Copy(conn, pbr)
+			return
+		}
+		if req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+func isHTTPRequest(br *bufio.Reader) bool {
+	b, _ := br.Peek(8)
+	for _, method := range httpMethods {
+		if strings.HasPrefix(string(b), method+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// pipeConn keeps the addresses of the client conn, which are used by the handler for logging and the client IP.
+type pipeConn struct {
+	net.Conn
+	local  net.Addr
+	remote net.Addr
+}
+
+func (c *pipeConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return c.remote
+}
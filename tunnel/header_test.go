@@ -0,0 +1,56 @@
+package tunnel
+
+import (
+	"reflect"
+	"testing"
+
+	cfg "github.com/go-gost/gost.plus/config"
+)
+
+func TestValidateHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []cfg.HTTPHeader
+		ok      bool
+	}{
+		{name: "none", ok: true},
+		{name: "set", headers: []cfg.HTTPHeader{{Name: "X-Env", Value: "staging"}}, ok: true},
+		{name: "empty value", headers: []cfg.HTTPHeader{{Name: "X-Env"}}, ok: true},
+		{name: "remove", headers: []cfg.HTTPHeader{{Name: "Server", Remove: true}}, ok: true},
+		{name: "token characters", headers: []cfg.HTTPHeader{{Name: "X-A_b.c~!#$%&'*+^`|", Value: "v"}}, ok: true},
+		{name: "empty name", headers: []cfg.HTTPHeader{{Value: "v"}}},
+		{name: "space in name", headers: []cfg.HTTPHeader{{Name: "X Env", Value: "v"}}},
+		{name: "colon in name", headers: []cfg.HTTPHeader{{Name: "X-Env:", Value: "v"}}},
+		{name: "non-ASCII name", headers: []cfg.HTTPHeader{{Name: "X-Ümlaut", Value: "v"}}},
+		{name: "CRLF in value", headers: []cfg.HTTPHeader{{Name: "X-Env", Value: "v\r\nSet-Cookie: a=b"}}},
+		{name: "LF in value", headers: []cfg.HTTPHeader{{Name: "X-Env", Value: "v\nw"}}},
+		{name: "second invalid", headers: []cfg.HTTPHeader{{Name: "X-Env", Value: "v"}, {Name: "(bad)"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateHeaders(tt.headers); (err == nil) != tt.ok {
+				t.Errorf("ValidateHeaders() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSetRemoveHeaders(t *testing.T) {
+	headers := []cfg.HTTPHeader{
+		{Name: "X-Env", Value: "staging"},
+		{Name: "Server", Remove: true},
+		{Name: "X-Powered-By", Remove: true},
+		{Name: "X-Empty"},
+	}
+
+	if got, want := setHeaders(headers), map[string]string{"X-Env": "staging", "X-Empty": ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("setHeaders() = %v, want %v", got, want)
+	}
+	if got, want := removeHeaders(headers), []string{"Server", "X-Powered-By"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removeHeaders() = %v, want %v", got, want)
+	}
+	if got := setHeaders(nil); got != nil {
+		t.Errorf("setHeaders(nil) = %v, want nil", got)
+	}
+}
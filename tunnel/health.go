@@ -0,0 +1,203 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	chain_parser "github.com/go-gost/x/config/parsing/chain"
+)
+
+const (
+	// HealthCheckInterval is the interval of the periodic health checks.
+	HealthCheckInterval = 30 * time.Second
+	// HealthCheckTimeout is the timeout of a single probe.
+	HealthCheckTimeout = 10 * time.Second
+	// MaxHealthyLatency is the latency to the server above which a tunnel is considered degraded.
+	MaxHealthyLatency = time.Second
+)
+
+type HealthState string
+
+const (
+	HealthUnknown  HealthState = ""
+	HealthUp       HealthState = "up"
+	HealthDegraded HealthState = "degraded"
+	HealthDown     HealthState = "down"
+)
+
+var (
+	ErrNoRoute = errors.New("no route to server")
+)
+
+// Health is the result of the latest health check of a tunnel.
+type Health struct {
+	State HealthState
+	// Latency is the round-trip time of connecting to the server through the chain.
+	Latency time.Duration
+	// ServerErr is the error of the server probe.
+	ServerErr error
+	// EndpointErr is the error of the local endpoint probe.
+	EndpointErr error
+	// CheckedAt is the time of the latest check.
+	CheckedAt time.Time
+	// Since is the time when the tunnel entered the current state.
+	Since time.Time
+}
+
+var (
+	healths   = make(map[string]Health)
+	healthsMu sync.RWMutex
+)
+
+// GetHealth returns the latest health check result of the tunnel, ok is false if the tunnel has not been checked yet.
+func GetHealth(id string) (health Health, ok bool) {
+	healthsMu.RLock()
+	defer healthsMu.RUnlock()
+
+	health, ok = healths[id]
+	return
+}
+
+// CheckHealth checks all the running tunnels concurrently and records the results.
+func CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < Count(); i++ {
+		tun := GetIndex(i)
+		if tun == nil {
+			continue
+		}
+		if tun.IsClosed() {
+			healthsMu.Lock()
+			delete(healths, tun.ID())
+			healthsMu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			setHealth(tun.ID(), checkHealth(ctx, tun))
+		}()
+	}
+	wg.Wait()
+}
+
+func setHealth(id string, health Health) {
+	healthsMu.Lock()
+	defer healthsMu.Unlock()
+
+	health.Since = health.CheckedAt
+	if old, ok := healths[id]; ok && old.State == health.State {
+		health.Since = old.Since
+	}
+	healths[id] = health
+}
+
+func checkHealth(ctx context.Context, tun Tunnel) Health {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	health := Health{
+		CheckedAt: time.Now(),
+	}
+	health.Latency, health.ServerErr = probeServer(ctx, tun)
+	health.EndpointErr = probeEndpoint(ctx, tun)
+
+	switch {
+	case health.ServerErr != nil || tun.Err() != nil:
+		health.State = HealthDown
+	case health.EndpointErr != nil || health.Latency > MaxHealthyLatency:
+		health.State = HealthDegraded
+	default:
+		health.State = HealthUp
+	}
+	return health
+}
+
+// probeServer connects to the server through the chain of the tunnel and returns the time it takes.
+func probeServer(ctx context.Context, tun Tunnel) (time.Duration, error) {
+	log := logger.Default().WithFields(map[string]any{
+		"kind":    "health",
+		"service": tun.Name(),
+	})
+	ch, err := chain_parser.ParseChain(ChainConfig(tun.ID(), tun.Name(), tun.Options().Transport, tun.Options().Proxies), log)
+	if err != nil {
+		return 0, err
+	}
+	route := ch.Route(ctx, "tcp", ServerAddr())
+	if route == nil || len(route.Nodes()) == 0 {
+		return 0, ErrNoRoute
+	}
+	nodes := route.Nodes()
+
+	start := time.Now()
+
+	node := nodes[0]
+	cc, err := node.Options().Transport.Dial(ctx, node.Addr)
+	if err != nil {
+		return 0, err
+	}
+	conn, err := node.Options().Transport.Handshake(ctx, cc)
+	if err != nil {
+		cc.Close()
+		return 0, err
+	}
+
+	prev := node
+	for _, node := range nodes[1:] {
+		if cc, err = prev.Options().Transport.Connect(ctx, conn, "tcp", node.Addr); err != nil {
+			conn.Close()
+			return 0, err
+		}
+		if cc, err = node.Options().Transport.Handshake(ctx, cc); err != nil {
+			conn.Close()
+			return 0, err
+		}
+		conn = cc
+		prev = node
+	}
+	conn.Close()
+
+	return time.Since(start), nil
+}
+
+// probeEndpoint checks whether the local endpoint of the tunnel is reachable.
+// The UDP endpoints are not probed as there is no reliable way to do it.
+func probeEndpoint(ctx context.Context, tun Tunnel) error {
+	switch tun.Type() {
+	case FileTunnel:
+		fi, err := os.Stat(tun.Endpoint())
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return errors.New("not a directory")
+		}
+		return nil
+
+	case HTTPTunnel, TCPTunnel:
+		// the failed backends are also skipped by the selector until they are reachable again.
+		if checker, ok := tun.(backendChecker); ok {
+			return checker.checkBackends(ctx)
+		}
+
+		var errs []error
+		for _, endpoint := range Endpoints(tun.Endpoint()) {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", endpoint)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			conn.Close()
+		}
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package history
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is a snapshot of the traffic rates of a service.
+type Sample struct {
+	Time            time.Time
+	InputRateBytes  uint64
+	OutputRateBytes uint64
+	RequestRate     float64
+	CurrentConns    uint64
+}
+
+// Retention is the resolution and the time span of a downsampled series.
+type Retention struct {
+	Resolution time.Duration
+	Span       time.Duration
+}
+
+var (
+	// DefaultRetentions keeps 1s samples for an hour, 1m samples for a day and 1h samples for a month.
+	DefaultRetentions = []Retention{
+		{Resolution: time.Second, Span: time.Hour},
+		{Resolution: time.Minute, Span: 24 * time.Hour},
+		{Resolution: time.Hour, Span: 30 * 24 * time.Hour},
+	}
+)
+
+// series is a ring buffer of samples with a fixed resolution,
+// the samples within the same interval are averaged into one.
+type series struct {
+	resolution time.Duration
+	samples    []Sample
+	next       int
+	count      int
+
+	// the samples of the current interval not yet added to the ring buffer.
+	bucket time.Time
+	sum    Sample
+	n      int
+}
+
+func newSeries(r Retention) *series {
+	size := int(r.Span / r.Resolution)
+	if size <= 0 {
+		size = 1
+	}
+	return &series{
+		resolution: r.Resolution,
+		samples:    make([]Sample, size),
+	}
+}
+
+func (s *series) add(sample Sample) {
+	bucket := sample.Time.Truncate(s.resolution)
+	if s.n > 0 && !bucket.Equal(s.bucket) {
+		s.flush()
+	}
+
+	s.bucket = bucket
+	s.sum.InputRateBytes += sample.InputRateBytes
+	s.sum.OutputRateBytes += sample.OutputRateBytes
+	s.sum.RequestRate += sample.RequestRate
+	s.sum.CurrentConns += sample.CurrentConns
+	s.n++
+}
+
+func (s *series) flush() {
+	if s.n == 0 {
+		return
+	}
+
+	n := uint64(s.n)
+	s.samples[s.next] = Sample{
+		Time:            s.bucket,
+		InputRateBytes:  s.sum.InputRateBytes / n,
+		OutputRateBytes: s.sum.OutputRateBytes / n,
+		RequestRate:     s.sum.RequestRate / float64(s.n),
+		CurrentConns:    s.sum.CurrentConns / n,
+	}
+	s.next = (s.next + 1) % len(s.samples)
+	if s.count < len(s.samples) {
+		s.count++
+	}
+
+	s.sum = Sample{}
+	s.n = 0
+}
+
+// list returns the samples after since in time order, including the incomplete current interval.
+func (s *series) list(since time.Time) []Sample {
+	samples := make([]Sample, 0, s.count+1)
+	for i := s.count; i > 0; i-- {
+		sample := s.samples[(s.next-i+len(s.samples))%len(s.samples)]
+		if sample.Time.Before(since) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if s.n > 0 && !s.bucket.Before(since) {
+		n := uint64(s.n)
+		samples = append(samples, Sample{
+			Time:            s.bucket,
+			InputRateBytes:  s.sum.InputRateBytes / n,
+			OutputRateBytes: s.sum.OutputRateBytes / n,
+			RequestRate:     s.sum.RequestRate / float64(s.n),
+			CurrentConns:    s.sum.CurrentConns / n,
+		})
+	}
+	return samples
+}
+
+// History keeps the samples of a service in several series of decreasing resolution.
+type History struct {
+	series []*series
+	mu     sync.RWMutex
+}
+
+func NewHistory(retentions ...Retention) *History {
+	if len(retentions) == 0 {
+		retentions = DefaultRetentions
+	}
+
+	h := &History{}
+	for _, r := range retentions {
+		h.series = append(h.series, newSeries(r))
+	}
+	return h
+}
+
+func (h *History) Add(sample Sample) {
+	if sample.Time.IsZero() {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, s := range h.series {
+		s.add(sample)
+	}
+}
+
+// Samples returns the samples of the last d and their resolution, taken from the finest series spanning d.
+func (h *History) Samples(d time.Duration) ([]Sample, time.Duration) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.series) == 0 {
+		return nil, 0
+	}
+
+	s := h.series[len(h.series)-1]
+	for _, v := range h.series {
+		if time.Duration(len(v.samples))*v.resolution >= d {
+			s = v
+			break
+		}
+	}
+	return s.list(time.Now().Add(-d)), s.resolution
+}
+
+// Registry holds the histories of a kind of services by ID.
+type Registry struct {
+	histories map[string]*History
+	mu        sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		histories: make(map[string]*History),
+	}
+}
+
+// Get returns the history of the service, the history is created if it does not exist.
+func (r *Registry) Get(id string) *History {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h := r.histories[id]
+	if h == nil {
+		h = NewHistory()
+		r.histories[id] = h
+	}
+	return h
+}
+
+// Lookup returns the history of the service, nil is returned if it does not exist.
+func (r *Registry) Lookup(id string) *History {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.histories[id]
+}
+
+func (r *Registry) Delete(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.histories, id)
+}
+
+var (
+	Tunnels     = NewRegistry()
+	EntryPoints = NewRegistry()
+)
@@ -0,0 +1,147 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeriesDownsampling(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    []Sample
+	}{
+		{
+			name: "empty",
+			want: []Sample{},
+		},
+		{
+			name: "current interval",
+			samples: []Sample{
+				{Time: t0, InputRateBytes: 10, OutputRateBytes: 20, RequestRate: 1, CurrentConns: 2},
+				{Time: t0.Add(30 * time.Second), InputRateBytes: 30, OutputRateBytes: 40, RequestRate: 2, CurrentConns: 4},
+			},
+			want: []Sample{
+				{Time: t0, InputRateBytes: 20, OutputRateBytes: 30, RequestRate: 1.5, CurrentConns: 3},
+			},
+		},
+		{
+			name: "averaged",
+			samples: []Sample{
+				{Time: t0.Add(10 * time.Second), InputRateBytes: 10},
+				{Time: t0.Add(50 * time.Second), InputRateBytes: 20},
+				{Time: t0.Add(time.Minute), InputRateBytes: 100},
+				{Time: t0.Add(3*time.Minute + time.Second), InputRateBytes: 7},
+			},
+			want: []Sample{
+				{Time: t0, InputRateBytes: 15},
+				{Time: t0.Add(time.Minute), InputRateBytes: 100},
+				{Time: t0.Add(3 * time.Minute), InputRateBytes: 7},
+			},
+		},
+		{
+			name: "overwritten",
+			samples: []Sample{
+				{Time: t0, InputRateBytes: 1},
+				{Time: t0.Add(time.Minute), InputRateBytes: 2},
+				{Time: t0.Add(2 * time.Minute), InputRateBytes: 3},
+				{Time: t0.Add(3 * time.Minute), InputRateBytes: 4},
+				{Time: t0.Add(4 * time.Minute), InputRateBytes: 5},
+			},
+			want: []Sample{
+				{Time: t0.Add(time.Minute), InputRateBytes: 2},
+				{Time: t0.Add(2 * time.Minute), InputRateBytes: 3},
+				{Time: t0.Add(3 * time.Minute), InputRateBytes: 4},
+				{Time: t0.Add(4 * time.Minute), InputRateBytes: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSeries(Retention{Resolution: time.Minute, Span: 3 * time.Minute})
+			for _, sample := range tt.samples {
+				s.add(sample)
+			}
+			assertSamples(t, s.list(time.Time{}), tt.want)
+		})
+	}
+}
+
+func TestSeriesListSince(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := newSeries(Retention{Resolution: time.Minute, Span: time.Hour})
+	for i := 0; i < 5; i++ {
+		s.add(Sample{Time: t0.Add(time.Duration(i) * time.Minute), CurrentConns: uint64(i)})
+	}
+
+	assertSamples(t, s.list(t0.Add(3*time.Minute)), []Sample{
+		{Time: t0.Add(3 * time.Minute), CurrentConns: 3},
+		{Time: t0.Add(4 * time.Minute), CurrentConns: 4},
+	})
+	assertSamples(t, s.list(t0.Add(time.Hour)), []Sample{})
+}
+
+func TestHistorySamples(t *testing.T) {
+	h := NewHistory(
+		Retention{Resolution: time.Second, Span: time.Minute},
+		Retention{Resolution: time.Minute, Span: time.Hour},
+	)
+	h.Add(Sample{})
+
+	now := time.Now()
+	for i := 0; i < 90; i++ {
+		h.Add(Sample{Time: now.Add(time.Duration(i-90) * time.Second), InputRateBytes: 1})
+	}
+
+	tests := []struct {
+		d          time.Duration
+		resolution time.Duration
+	}{
+		{d: 30 * time.Second, resolution: time.Second},
+		{d: time.Minute, resolution: time.Second},
+		{d: 10 * time.Minute, resolution: time.Minute},
+		{d: 24 * time.Hour, resolution: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.d.String(), func(t *testing.T) {
+			samples, resolution := h.Samples(tt.d)
+			if resolution != tt.resolution {
+				t.Errorf("got resolution %s, want %s", resolution, tt.resolution)
+			}
+			if len(samples) == 0 {
+				t.Fatal("no samples")
+			}
+			since := time.Now().Add(-tt.d).Truncate(resolution)
+			for _, sample := range samples {
+				if sample.Time.Before(since) {
+					t.Errorf("sample at %s is before %s", sample.Time, since)
+				}
+				if sample.InputRateBytes != 1 {
+					t.Errorf("sample at %s has input rate %d, want 1", sample.Time, sample.InputRateBytes)
+				}
+			}
+		})
+	}
+}
+
+func assertSamples(t *testing.T, got, want []Sample) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d samples %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range got {
+		if !got[i].Time.Equal(want[i].Time) ||
+			got[i].InputRateBytes != want[i].InputRateBytes ||
+			got[i].OutputRateBytes != want[i].OutputRateBytes ||
+			got[i].RequestRate != want[i].RequestRate ||
+			got[i].CurrentConns != want[i].CurrentConns {
+			t.Errorf("sample %d is %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-gost/gost.plus/config"
+)
+
+const (
+	historyFile = "history.json"
+	// PersistResolution is the finest resolution of the series kept across restarts,
+	// the finer ones are only kept in memory.
+	PersistResolution = time.Hour
+)
+
+// seriesState is the persisted samples of a series, the last one is the current interval.
+type seriesState struct {
+	Resolution time.Duration `json:"resolution"`
+	Samples    []Sample      `json:"samples"`
+}
+
+// store is the content of the history file, the series of the services by ID.
+type store struct {
+	Tunnels     map[string][]seriesState `json:"tunnels,omitempty"`
+	EntryPoints map[string][]seriesState `json:"entrypoints,omitempty"`
+}
+
+// Load restores the persisted series of the tunnels and entrypoints from the history file in the config directory.
+func Load() error {
+	b, err := os.ReadFile(filepath.Join(config.Dir(), historyFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	st := &store{}
+	if err := json.Unmarshal(b, st); err != nil {
+		return err
+	}
+
+	Tunnels.restore(st.Tunnels)
+	EntryPoints.restore(st.EntryPoints)
+	return nil
+}
+
+// Save persists the series of at least PersistResolution of the tunnels and entrypoints to the history file.
+func Save() error {
+	b, err := json.Marshal(&store{
+		Tunnels:     Tunnels.state(),
+		EntryPoints: EntryPoints.state(),
+	})
+	if err != nil {
+		return err
+	}
+	return config.WriteFile(historyFile, b)
+}
+
+func (r *Registry) state() map[string][]seriesState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m := make(map[string][]seriesState)
+	for id, h := range r.histories {
+		if st := h.state(); st != nil {
+			m[id] = st
+		}
+	}
+	return m
+}
+
+func (r *Registry) restore(m map[string][]seriesState) {
+	for id, st := range m {
+		r.Get(id).restore(st)
+	}
+}
+
+// state returns the persisted series, nil if all of them are empty.
+func (h *History) state() []seriesState {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var states []seriesState
+	var n int
+	for _, s := range h.series {
+		if s.resolution < PersistResolution {
+			continue
+		}
+		// samples out of the span are dropped, so that the history of a removed service expires.
+		samples := s.list(time.Now().Add(-time.Duration(len(s.samples)) * s.resolution))
+		n += len(samples)
+		states = append(states, seriesState{
+			Resolution: s.resolution,
+			Samples:    samples,
+		})
+	}
+	if n == 0 {
+		return nil
+	}
+	return states
+}
+
+func (h *History) restore(states []seriesState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, st := range states {
+		for _, s := range h.series {
+			if s.resolution == st.Resolution {
+				s.restore(st.Samples)
+			}
+		}
+	}
+}
+
+// restore replaces the samples of the series, the last sample becomes the current interval
+// so that the samples added after a quick restart are merged into it.
+func (s *series) restore(samples []Sample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	last := samples[len(samples)-1]
+	samples = samples[:len(samples)-1]
+	if len(samples) > len(s.samples) {
+		samples = samples[len(samples)-len(s.samples):]
+	}
+
+	copy(s.samples, samples)
+	s.count = len(samples)
+	s.next = s.count % len(s.samples)
+
+	s.bucket = last.Time.Truncate(s.resolution)
+	s.sum = last
+	s.n = 1
+}
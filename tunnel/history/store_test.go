@@ -0,0 +1,88 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestHistoryStateRestore(t *testing.T) {
+	now := time.Now().Truncate(time.Hour)
+
+	h := NewHistory()
+	for i := 5; i >= 0; i-- {
+		h.Add(Sample{Time: now.Add(-time.Duration(i) * time.Hour), InputRateBytes: uint64(i)})
+	}
+
+	states := h.state()
+	if len(states) != 1 || states[0].Resolution != PersistResolution {
+		t.Fatalf("got %d series, want the one of %s", len(states), PersistResolution)
+	}
+	if n := len(states[0].Samples); n != 6 {
+		t.Fatalf("got %d persisted samples, want 6", n)
+	}
+
+	// the state is persisted as JSON.
+	b, err := json.Marshal(states)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored []seriesState
+	if err := json.Unmarshal(b, &restored); err != nil {
+		t.Fatal(err)
+	}
+
+	h2 := NewHistory()
+	h2.restore(restored)
+	samples, resolution := h2.Samples(7 * 24 * time.Hour)
+	if resolution != time.Hour {
+		t.Fatalf("got resolution %s, want 1h", resolution)
+	}
+	assertSamples(t, samples, states[0].Samples)
+
+	// the samples after a restart are merged into the restored current interval.
+	h2.Add(Sample{Time: now.Add(time.Minute), InputRateBytes: 10})
+	samples, _ = h2.Samples(7 * 24 * time.Hour)
+	if last := samples[len(samples)-1]; !last.Time.Equal(now) || last.InputRateBytes != 5 {
+		t.Errorf("got the current interval %+v, want the average 5 at %s", last, now)
+	}
+}
+
+func TestHistoryStateEmpty(t *testing.T) {
+	h := NewHistory()
+	// the samples out of the span of the persisted series are dropped.
+	h.Add(Sample{Time: time.Now().Add(-48 * 24 * time.Hour), InputRateBytes: 1})
+	if st := h.state(); st != nil {
+		t.Errorf("got %v, want no state for samples out of the span", st)
+	}
+
+	r := NewRegistry()
+	r.Get("a")
+	r.Get("b").Add(Sample{Time: time.Now(), InputRateBytes: 1})
+	m := r.state()
+	if _, ok := m["a"]; ok {
+		t.Errorf("empty history is persisted")
+	}
+	if _, ok := m["b"]; !ok {
+		t.Errorf("history is not persisted")
+	}
+
+	r2 := NewRegistry()
+	r2.restore(m)
+	if r2.Lookup("b") == nil || r2.Lookup("a") != nil {
+		t.Errorf("got the restored histories a=%v b=%v", r2.Lookup("a"), r2.Lookup("b"))
+	}
+}
+
+func TestSeriesRestoreOverflow(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var samples []Sample
+	for i := 0; i < 6; i++ {
+		samples = append(samples, Sample{Time: t0.Add(time.Duration(i) * time.Hour), CurrentConns: uint64(i)})
+	}
+
+	s := newSeries(Retention{Resolution: time.Hour, Span: 3 * time.Hour})
+	s.restore(samples)
+	assertSamples(t, s.list(time.Time{}), samples[2:])
+}
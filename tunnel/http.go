@@ -1,9 +1,17 @@
 package tunnel
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/tls"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,31 +22,44 @@ import (
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/core/service"
 	cfg "github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/tunnel/inspector"
 	xauth "github.com/go-gost/x/auth"
 	xchain "github.com/go-gost/x/chain"
 	"github.com/go-gost/x/config"
 	chain_parser "github.com/go-gost/x/config/parsing/chain"
+	selector_parser "github.com/go-gost/x/config/parsing/selector"
 	"github.com/go-gost/x/handler/forward/remote"
 	"github.com/go-gost/x/hop"
 	"github.com/go-gost/x/listener/rtcp"
 	mdx "github.com/go-gost/x/metadata"
 	xstats "github.com/go-gost/x/observer/stats"
+	"github.com/go-gost/x/routing"
 	xservice "github.com/go-gost/x/service"
 	"github.com/google/uuid"
 )
 
+const (
+	replayTimeout = 30 * time.Second
+)
+
 type httpTunnel struct {
 	endpoint string
 	opts     Options
 	config   *config.Config
 	forward  service.Service
+	handler  handler.Handler
+	backends *backendGroup
+	// tls connects to the endpoints if EnableTLS is true.
+	tls      *tls.Config
 	favorite atomic.Bool
 	stats    cfg.ServiceStats
 
 	cclose chan struct{}
 
 	err error
-	mu  sync.RWMutex
+	// backendErr is the failed TLS handshake with an endpoint, see BackendErrorer.
+	backendErr error
+	mu         sync.RWMutex
 }
 
 func NewHTTPTunnel(opts ...Option) Tunnel {
@@ -91,7 +112,7 @@ func (s *httpTunnel) Endpoint() string {
 }
 
 func (s *httpTunnel) Entrypoint() string {
-	return fmt.Sprintf("https://%s.%s", s.endpoint, EndpointAddr)
+	return fmt.Sprintf("https://%s.%s", s.endpoint, EndpointAddr())
 }
 
 func (s *httpTunnel) Options() Options {
@@ -107,23 +128,50 @@ func (s *httpTunnel) IsFavorite() bool {
 }
 
 func (s *httpTunnel) init() error {
-	node := &config.ForwardNodeConfig{
+	if err := ValidateRoutes(s.opts.Routes); err != nil {
+		return err
+	}
+	if err := ValidateHeaders(s.opts.RequestHeaders); err != nil {
+		return err
+	}
+	if err := ValidateHeaders(s.opts.ResponseHeaders); err != nil {
+		return err
+	}
+
+	endpoints := Endpoints(s.opts.Endpoint)
+
+	tmpl := &config.ForwardNodeConfig{
 		Name: s.opts.Name,
-		Addr: s.opts.Endpoint,
-		HTTP: &config.HTTPNodeConfig{},
+		HTTP: &config.HTTPNodeConfig{
+			RequestHeader:  setHeaders(s.opts.RequestHeaders),
+			ResponseHeader: setHeaders(s.opts.ResponseHeaders),
+		},
 	}
 	if s.opts.Username != "" {
-		node.HTTP.Auth = &config.AuthConfig{
+		tmpl.HTTP.Auth = &config.AuthConfig{
 			Username: s.opts.Username,
 			Password: s.opts.Password,
 		}
 	}
 	if s.opts.Hostname != "" {
-		node.HTTP.Host = s.opts.Hostname
+		tmpl.HTTP.Host = s.opts.Hostname
 	}
+	s.tls = nil
 	if s.opts.EnableTLS {
-		node.TLS = &config.TLSNodeConfig{}
+		tlsConfig, err := tlsConfig(s.opts.TLS)
+		if err != nil {
+			return err
+		}
+		s.tls = tlsConfig
+	}
+
+	var nodes []*config.ForwardNodeConfig
+	for _, endpoint := range endpoints {
+		node := *tmpl
+		node.Addr = endpoint
+		nodes = append(nodes, &node)
 	}
+	nodes = append(nodes, routeNodes(s.opts.Routes, tmpl)...)
 
 	rtcp := &config.ServiceConfig{
 		Name: s.opts.Name,
@@ -139,13 +187,14 @@ func (s *httpTunnel) init() error {
 			Chain: s.opts.Name,
 		},
 		Forwarder: &config.ForwarderConfig{
-			Nodes: []*config.ForwardNodeConfig{node},
+			Nodes:    nodes,
+			Selector: selectorConfig(s.opts.Strategy, len(endpoints)),
 		},
 	}
 
 	s.config = &config.Config{
 		Services: []*config.ServiceConfig{rtcp},
-		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name)},
+		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name, s.opts.Transport, s.opts.Proxies)},
 	}
 	return nil
 }
@@ -189,38 +238,48 @@ func (s *httpTunnel) Run() (err error) {
 			return
 		}
 
+		var nodes []*chain.Node
+		for _, node := range cfg.Forwarder.Nodes {
+			nodes = append(nodes, newHTTPNode(node))
+		}
+		backends := newBackendGroup(nodes)
+
 		handlerLogger := log.WithFields(map[string]any{"kind": "handler", "handler": "rtcp"})
+		var router chain.Router = xchain.NewRouter(chain.LoggerRouterOption(handlerLogger))
+		if s.tls != nil {
+			router = &tlsRouter{
+				Router:    router,
+				config:    s.tls,
+				handshake: s.tlsHandshake,
+			}
+		}
 		h := remote.NewHandler(
-			handler.RouterOption(xchain.NewRouter(chain.LoggerRouterOption(handlerLogger))),
+			handler.RouterOption(backends.Router(router)),
 			handler.LoggerOption(handlerLogger),
+			handler.RecordersOption(inspector.Get(s.opts.ID).RecorderObject()),
 		)
 		if err = h.Init(mdx.NewMetadata(cfg.Handler.Metadata)); err != nil {
 			return
 		}
 
-		node := cfg.Forwarder.Nodes[0]
-		var nodeOpts []chain.NodeOption
-		if node.HTTP != nil {
-			httpNodeSettings := &chain.HTTPNodeSettings{
-				Host:          node.HTTP.Host,
-				RequestHeader: node.HTTP.RequestHeader,
-			}
-			if node.HTTP.Auth != nil {
-				httpNodeSettings.Auther = xauth.NewAuthenticator(xauth.AuthsOption(map[string]string{node.HTTP.Auth.Username: node.HTTP.Auth.Password}))
-			}
-			nodeOpts = append(nodeOpts, chain.HTTPNodeOption(httpNodeSettings))
-		}
-		if node.TLS != nil {
-			nodeOpts = append(nodeOpts, chain.TLSNodeOption(&chain.TLSNodeSettings{
-				ServerName: node.TLS.ServerName,
-				Secure:     node.TLS.Secure,
-			}))
-		}
 		if forwarder, ok := h.(handler.Forwarder); ok {
-			forwarder.Forward(hop.NewHop(hop.NodeOption(chain.NewNode(node.Name, node.Addr, nodeOpts...)),
+			forwarder.Forward(hop.NewHop(
+				hop.NodeOption(nodes...),
+				hop.SelectorOption(selector_parser.ParseNodeSelector(cfg.Forwarder.Selector)),
 				hop.LoggerOption(log.WithFields(map[string]any{"kind": "hop"})),
 			))
 		}
+		if req, resp := removeHeaders(s.opts.RequestHeaders), removeHeaders(s.opts.ResponseHeaders); req != nil || resp != nil {
+			h = &headerHandler{
+				Handler:  h,
+				request:  req,
+				response: resp,
+			}
+		}
+		s.mu.Lock()
+		s.handler = h
+		s.backends = backends
+		s.mu.Unlock()
 		s.forward = xservice.NewService(s.opts.Name, ln, h,
 			xservice.LoggerOption(log),
 			xservice.StatsOption(stats),
@@ -234,6 +293,87 @@ func (s *httpTunnel) Run() (err error) {
 	return nil
 }
 
+// newHTTPNode creates the forwarding node to a local HTTP endpoint.
+func newHTTPNode(node *config.ForwardNodeConfig) *chain.Node {
+	var nodeOpts []chain.NodeOption
+	if node.HTTP != nil {
+		httpNodeSettings := &chain.HTTPNodeSettings{
+			Host:           node.HTTP.Host,
+			RequestHeader:  node.HTTP.RequestHeader,
+			ResponseHeader: node.HTTP.ResponseHeader,
+		}
+		if node.HTTP.Auth != nil {
+			httpNodeSettings.Auther = xauth.NewAuthenticator(xauth.AuthsOption(map[string]string{node.HTTP.Auth.Username: node.HTTP.Auth.Password}))
+		}
+		for _, rewrite := range node.HTTP.RewriteURL {
+			if pattern, err := regexp.Compile(rewrite.Match); err == nil {
+				httpNodeSettings.RewriteURL = append(httpNodeSettings.RewriteURL, chain.HTTPURLRewriteSetting{
+					Pattern:     pattern,
+					Replacement: rewrite.Replacement,
+				})
+			}
+		}
+		nodeOpts = append(nodeOpts, chain.HTTPNodeOption(httpNodeSettings))
+	}
+	if node.TLS != nil {
+		nodeOpts = append(nodeOpts, chain.TLSNodeOption(&chain.TLSNodeSettings{
+			ServerName: node.TLS.ServerName,
+			Secure:     node.TLS.Secure,
+		}))
+	}
+	if node.Matcher != nil {
+		if matcher, err := routing.NewMatcher(node.Matcher.Rule); err == nil {
+			nodeOpts = append(nodeOpts,
+				chain.MatcherNodeOption(matcher),
+				chain.PriorityNodeOption(node.Matcher.Priority),
+			)
+		}
+	}
+	return chain.NewNode(node.Name, node.Addr, nodeOpts...)
+}
+
+// Replay sends the request to the endpoint through the forwarding handler of the tunnel,
+// so it is processed in the same way as the real traffic and captured by the inspector.
+func (s *httpTunnel) Replay(ctx context.Context, req *http.Request) (*http.Response, error) {
+	s.mu.RLock()
+	h := s.handler
+	s.mu.RUnlock()
+	if s.IsClosed() || h == nil {
+		return nil, ErrTunnelClosed
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, replayTimeout)
+	defer cancel()
+
+	conn, hc := net.Pipe()
+	defer conn.Close()
+
+	go h.Handle(ctx, hc)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req.Close = true
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
 func (s *httpTunnel) Status() *xservice.Status {
 	if ss, _ := s.forward.(ServiceStatus); ss != nil {
 		return ss.Status()
@@ -241,6 +381,19 @@ func (s *httpTunnel) Status() *xservice.Status {
 	return nil
 }
 
+func (s *httpTunnel) Backends() []BackendStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.backends.Stats()
+}
+
+func (s *httpTunnel) checkBackends(ctx context.Context) error {
+	s.mu.RLock()
+	backends := s.backends
+	s.mu.RUnlock()
+	return backends.Check(ctx)
+}
+
 func (s *httpTunnel) Stats() cfg.ServiceStats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -283,6 +436,19 @@ func (s *httpTunnel) setErr(err error) {
 	s.err = err
 }
 
+// tlsHandshake reports the failed handshake with an endpoint by BackendErr, until a handshake succeeds.
+func (s *httpTunnel) tlsHandshake(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backendErr = err
+}
+
+func (s *httpTunnel) BackendErr() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.backendErr
+}
+
 func (s *httpTunnel) Err() error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
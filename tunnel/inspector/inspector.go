@@ -0,0 +1,226 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/recorder"
+	xrecorder "github.com/go-gost/x/recorder"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultCapacity is the maximum number of records kept for each tunnel.
+	DefaultCapacity = 100
+	// MaxBodySize is the maximum size of the captured request/response body.
+	MaxBodySize = 64 * 1024
+)
+
+// ErrTruncatedBody is returned when a request is rebuilt from a body which is cut off at MaxBodySize.
+var ErrTruncatedBody = errors.New("the captured body is incomplete")
+
+type Record struct {
+	ID         string
+	Time       time.Time
+	Duration   time.Duration
+	RemoteAddr string
+	ClientIP   string
+	Host       string
+	Method     string
+	Proto      string
+	URI        string
+	StatusCode int
+	Request    Message
+	Response   Message
+	Err        string
+}
+
+type Message struct {
+	Header        http.Header
+	ContentLength int64
+	Body          []byte
+}
+
+// Truncated reports whether the captured body is cut off at MaxBodySize.
+// The size of a chunked body is unknown, a body of MaxBodySize is then taken as cut off.
+func (m *Message) Truncated() bool {
+	if m.ContentLength >= 0 {
+		return m.ContentLength > int64(len(m.Body))
+	}
+	return len(m.Body) >= MaxBodySize
+}
+
+// HTTPRequest builds a new HTTP request from the captured record, it fails if the captured body is incomplete.
+func (r *Record) HTTPRequest() (*http.Request, error) {
+	if r.Request.Truncated() {
+		return nil, ErrTruncatedBody
+	}
+
+	req, err := http.NewRequest(r.Method, r.URI, bytes.NewReader(r.Request.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Host = r.Host
+	if r.Request.Header != nil {
+		req.Header = r.Request.Header.Clone()
+	}
+	req.ContentLength = int64(len(r.Request.Body))
+	req.Header.Del("Content-Length")
+	req.Header.Del("Transfer-Encoding")
+
+	return req, nil
+}
+
+// Inspector keeps the latest HTTP exchanges of a tunnel in a ring buffer.
+// It implements the recorder.Recorder interface, so it can be attached to the service handler directly.
+type Inspector struct {
+	records []*Record
+	next    int
+	count   int
+	mu      sync.RWMutex
+}
+
+func NewInspector(capacity int) *Inspector {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Inspector{
+		records: make([]*Record, capacity),
+	}
+}
+
+func (p *Inspector) Record(ctx context.Context, b []byte, opts ...recorder.RecordOption) error {
+	var ro xrecorder.HandlerRecorderObject
+	if err := json.Unmarshal(b, &ro); err != nil {
+		return err
+	}
+	if ro.HTTP == nil {
+		return nil
+	}
+
+	p.Add(&Record{
+		ID:         uuid.NewString(),
+		Time:       ro.Time,
+		Duration:   ro.Duration,
+		RemoteAddr: ro.RemoteAddr,
+		ClientIP:   ro.ClientIP,
+		Host:       ro.HTTP.Host,
+		Method:     ro.HTTP.Method,
+		Proto:      ro.HTTP.Proto,
+		URI:        ro.HTTP.URI,
+		StatusCode: ro.HTTP.StatusCode,
+		Request: Message{
+			Header:        ro.HTTP.Request.Header,
+			ContentLength: ro.HTTP.Request.ContentLength,
+			Body:          ro.HTTP.Request.Body,
+		},
+		Response: Message{
+			Header:        ro.HTTP.Response.Header,
+			ContentLength: ro.HTTP.Response.ContentLength,
+			Body:          ro.HTTP.Response.Body,
+		},
+		Err: ro.Err,
+	})
+
+	return nil
+}
+
+func (p *Inspector) Add(r *Record) {
+	if r == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.records[p.next] = r
+	p.next = (p.next + 1) % len(p.records)
+	if p.count < len(p.records) {
+		p.count++
+	}
+}
+
+// Records returns the captured records, the latest first.
+func (p *Inspector) Records() []*Record {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	records := make([]*Record, 0, p.count)
+	for i := 1; i <= p.count; i++ {
+		index := (p.next - i + len(p.records)) % len(p.records)
+		records = append(records, p.records[index])
+	}
+	return records
+}
+
+func (p *Inspector) Get(id string) *Record {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, r := range p.records {
+		if r != nil && r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (p *Inspector) Count() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.count
+}
+
+func (p *Inspector) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range p.records {
+		p.records[i] = nil
+	}
+	p.next = 0
+	p.count = 0
+}
+
+func (p *Inspector) RecorderObject() recorder.RecorderObject {
+	return recorder.RecorderObject{
+		Recorder: p,
+		Record:   xrecorder.RecorderServiceHandler,
+		Options: &recorder.Options{
+			HTTPBody:    true,
+			MaxBodySize: MaxBodySize,
+		},
+	}
+}
+
+var (
+	inspectors = make(map[string]*Inspector)
+	mu         sync.Mutex
+)
+
+// Get returns the inspector of the tunnel, the inspector is created if it does not exist,
+// so the captured records survive tunnel restarts.
+func Get(id string) *Inspector {
+	mu.Lock()
+	defer mu.Unlock()
+
+	p := inspectors[id]
+	if p == nil {
+		p = NewInspector(DefaultCapacity)
+		inspectors[id] = p
+	}
+	return p
+}
+
+func Delete(id string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(inspectors, id)
+}
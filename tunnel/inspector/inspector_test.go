@@ -0,0 +1,146 @@
+package inspector
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestInspectorRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		add      int
+		want     []string
+	}{
+		{name: "empty", capacity: 3, add: 0, want: []string{}},
+		{name: "partial", capacity: 3, add: 2, want: []string{"1", "0"}},
+		{name: "full", capacity: 3, add: 3, want: []string{"2", "1", "0"}},
+		{name: "wrapped", capacity: 3, add: 5, want: []string{"4", "3", "2"}},
+		{name: "wrapped twice", capacity: 3, add: 7, want: []string{"6", "5", "4"}},
+		{name: "default capacity", capacity: 0, add: DefaultCapacity + 1, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewInspector(tt.capacity)
+			for i := 0; i < tt.add; i++ {
+				p.Add(&Record{ID: strconv.Itoa(i)})
+			}
+			p.Add(nil)
+
+			records := p.Records()
+			if tt.want == nil {
+				if len(records) != DefaultCapacity || p.Count() != DefaultCapacity {
+					t.Fatalf("got %d records, count %d, want %d", len(records), p.Count(), DefaultCapacity)
+				}
+				if records[0].ID != strconv.Itoa(tt.add-1) {
+					t.Errorf("latest record is %s, want %d", records[0].ID, tt.add-1)
+				}
+				return
+			}
+
+			if len(records) != len(tt.want) || p.Count() != len(tt.want) {
+				t.Fatalf("got %d records, count %d, want %d", len(records), p.Count(), len(tt.want))
+			}
+			for i, r := range records {
+				if r.ID != tt.want[i] {
+					t.Errorf("record %d is %s, want %s", i, r.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInspectorGetClear(t *testing.T) {
+	p := NewInspector(2)
+	for i := 0; i < 3; i++ {
+		p.Add(&Record{ID: strconv.Itoa(i)})
+	}
+
+	if r := p.Get("0"); r != nil {
+		t.Errorf("overwritten record 0 is found")
+	}
+	if r := p.Get("2"); r == nil || r.ID != "2" {
+		t.Errorf("record 2 is not found")
+	}
+
+	p.Clear()
+	if n := p.Count(); n != 0 {
+		t.Errorf("count is %d after clear", n)
+	}
+	if n := len(p.Records()); n != 0 {
+		t.Errorf("%d records after clear", n)
+	}
+	if r := p.Get("2"); r != nil {
+		t.Errorf("record 2 is found after clear")
+	}
+
+	p.Add(&Record{ID: "3"})
+	if records := p.Records(); len(records) != 1 || records[0].ID != "3" {
+		t.Errorf("got %v after clear and add, want [3]", records)
+	}
+}
+
+func TestMessageTruncated(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		want bool
+	}{
+		{name: "empty", msg: Message{}, want: false},
+		{name: "complete", msg: Message{ContentLength: 5, Body: []byte("hello")}, want: false},
+		{name: "cut off", msg: Message{ContentLength: MaxBodySize + 1, Body: make([]byte, MaxBodySize)}, want: true},
+		{name: "chunked", msg: Message{ContentLength: -1, Body: []byte("hello")}, want: false},
+		{name: "chunked at max size", msg: Message{ContentLength: -1, Body: make([]byte, MaxBodySize)}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.msg.Truncated(); got != tt.want {
+				t.Errorf("Truncated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordHTTPRequest(t *testing.T) {
+	r := &Record{
+		Method: "POST",
+		URI:    "http://example.com/api?q=1",
+		Host:   "app.example.com",
+		Request: Message{
+			Header: http.Header{
+				"Content-Type":      {"text/plain"},
+				"Transfer-Encoding": {"chunked"},
+			},
+			ContentLength: -1,
+			Body:          []byte("hello"),
+		},
+	}
+
+	req, err := r.HTTPRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Host != r.Host || req.Method != r.Method || req.URL.String() != r.URI {
+		t.Errorf("got %s %s host %s", req.Method, req.URL, req.Host)
+	}
+	if req.ContentLength != 5 || req.Header.Get("Transfer-Encoding") != "" {
+		t.Errorf("got content length %d, transfer encoding %q", req.ContentLength, req.Header.Get("Transfer-Encoding"))
+	}
+	if req.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("content type is not kept")
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != "hello" {
+		t.Errorf("got body %q", body)
+	}
+
+	r.Request.ContentLength = 10
+	if _, err := r.HTTPRequest(); !errors.Is(err, ErrTruncatedBody) {
+		t.Errorf("got error %v, want %v", err, ErrTruncatedBody)
+	}
+}
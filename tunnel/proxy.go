@@ -0,0 +1,283 @@
+package tunnel
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gost/core/auth"
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/handler"
+	"github.com/go-gost/core/listener"
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/service"
+	cfg "github.com/go-gost/gost.plus/config"
+	xauth "github.com/go-gost/x/auth"
+	xchain "github.com/go-gost/x/chain"
+	"github.com/go-gost/x/config"
+	chain_parser "github.com/go-gost/x/config/parsing/chain"
+	http_handler "github.com/go-gost/x/handler/http"
+	socks_handler "github.com/go-gost/x/handler/socks/v5"
+	"github.com/go-gost/x/listener/rtcp"
+	mdx "github.com/go-gost/x/metadata"
+	xstats "github.com/go-gost/x/observer/stats"
+	xservice "github.com/go-gost/x/service"
+	"github.com/google/uuid"
+)
+
+const (
+	ProxySOCKS5 = "socks5"
+	ProxyHTTP   = "http"
+)
+
+var (
+	ErrProxyAuth = errors.New("proxy tunnel requires a username and password")
+)
+
+// proxyTunnel exposes a proxy running in the client, so that the network of the client can be reached from the entrypoint.
+// The endpoint of the tunnel is the proxy protocol, ProxySOCKS5 or ProxyHTTP,
+// the clients must authenticate, otherwise anyone knowing the entrypoint could use the network of the client.
+type proxyTunnel struct {
+	endpoint string
+	opts     Options
+	config   *config.Config
+	proxy    service.Service
+	favorite atomic.Bool
+	stats    cfg.ServiceStats
+
+	cclose chan struct{}
+
+	err error
+	mu  sync.RWMutex
+}
+
+func NewProxyTunnel(opts ...Option) Tunnel {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.ID == "" {
+		options.ID = uuid.NewString()
+	}
+
+	v := md5.Sum([]byte(options.ID))
+	endpoint := hex.EncodeToString(v[:8])
+
+	if options.Endpoint == "" {
+		options.Endpoint = ProxySOCKS5
+	}
+
+	if options.Name == "" {
+		options.Name = endpoint
+	}
+	if options.CreatedAt.IsZero() {
+		options.CreatedAt = time.Now()
+	}
+
+	s := &proxyTunnel{
+		endpoint: endpoint,
+		opts:     options,
+		cclose:   make(chan struct{}),
+	}
+
+	return s
+}
+
+func (s *proxyTunnel) ID() string {
+	return s.opts.ID
+}
+
+func (s *proxyTunnel) Type() string {
+	return ProxyTunnel
+}
+
+func (s *proxyTunnel) Name() string {
+	return s.opts.Name
+}
+
+func (s *proxyTunnel) Endpoint() string {
+	return s.opts.Endpoint
+}
+
+func (s *proxyTunnel) Entrypoint() string {
+	return fmt.Sprintf("%s.%s", s.endpoint, EndpointAddr())
+}
+
+func (s *proxyTunnel) Options() Options {
+	return s.opts
+}
+
+func (s *proxyTunnel) Favorite(b bool) {
+	s.favorite.Store(b)
+}
+
+func (s *proxyTunnel) IsFavorite() bool {
+	return s.favorite.Load()
+}
+
+func (s *proxyTunnel) init() error {
+	switch s.opts.Endpoint {
+	case ProxySOCKS5, ProxyHTTP:
+	default:
+		return fmt.Errorf("unknown proxy protocol %s", s.opts.Endpoint)
+	}
+	if s.opts.Username == "" || s.opts.Password == "" {
+		return ErrProxyAuth
+	}
+
+	proxy := &config.ServiceConfig{
+		Name: s.opts.Name,
+		Addr: s.opts.Hostname,
+		Handler: &config.HandlerConfig{
+			Type: s.opts.Endpoint,
+		},
+		Listener: &config.ListenerConfig{
+			Type:  "rtcp",
+			Chain: s.opts.Name,
+		},
+	}
+	proxy.Handler.Auth = &config.AuthConfig{
+		Username: s.opts.Username,
+		Password: s.opts.Password,
+	}
+
+	s.config = &config.Config{
+		Services: []*config.ServiceConfig{proxy},
+		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name, s.opts.Transport, s.opts.Proxies)},
+	}
+	return nil
+}
+
+func (s *proxyTunnel) Run() (err error) {
+	if s.IsClosed() {
+		return ErrTunnelClosed
+	}
+
+	defer func() {
+		s.setErr(err)
+	}()
+
+	if err = s.init(); err != nil {
+		return
+	}
+
+	log := logger.Default().WithFields(map[string]any{
+		"kind":    "service",
+		"service": s.opts.Name,
+	})
+
+	{
+		var ch chain.Chainer
+		ch, err = chain_parser.ParseChain(s.config.Chains[0], log)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		listenerLogger := log.WithFields(map[string]any{"kind": "listener", "listener": "rtcp"})
+		stats := xstats.NewStats(false)
+		cfg := s.config.Services[0]
+		ln := rtcp.NewListener(
+			listener.AddrOption(cfg.Addr),
+			listener.RouterOption(xchain.NewRouter(chain.ChainRouterOption(ch), chain.LoggerRouterOption(listenerLogger))),
+			listener.LoggerOption(listenerLogger),
+			listener.StatsOption(stats),
+		)
+		if err = ln.Init(mdx.NewMetadata(cfg.Listener.Metadata)); err != nil {
+			return
+		}
+
+		var auther auth.Authenticator
+		if auth := cfg.Handler.Auth; auth != nil {
+			auther = xauth.NewAuthenticator(xauth.AuthsOption(map[string]string{auth.Username: auth.Password}))
+		}
+
+		// the proxy connects to the targets directly from the client.
+		handlerLogger := log.WithFields(map[string]any{"kind": "handler", "handler": cfg.Handler.Type})
+		handlerOpts := []handler.Option{
+			handler.RouterOption(xchain.NewRouter(chain.LoggerRouterOption(handlerLogger))),
+			handler.AutherOption(auther),
+			handler.LoggerOption(handlerLogger),
+		}
+		var h handler.Handler
+		if cfg.Handler.Type == ProxyHTTP {
+			h = http_handler.NewHandler(handlerOpts...)
+		} else {
+			h = socks_handler.NewHandler(handlerOpts...)
+		}
+		if err = h.Init(mdx.NewMetadata(cfg.Handler.Metadata)); err != nil {
+			return
+		}
+
+		s.proxy = xservice.NewService(s.opts.Name, ln, h,
+			xservice.LoggerOption(log),
+			xservice.StatsOption(stats),
+		)
+	}
+
+	go func() {
+		s.setErr(s.proxy.Serve())
+	}()
+
+	return nil
+}
+
+func (s *proxyTunnel) Status() *xservice.Status {
+	if ss, _ := s.proxy.(ServiceStatus); ss != nil {
+		return ss.Status()
+	}
+	return nil
+}
+
+func (s *proxyTunnel) Stats() cfg.ServiceStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats
+}
+
+func (s *proxyTunnel) SetStats(stats cfg.ServiceStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = stats
+}
+
+func (s *proxyTunnel) Close() error {
+	defer func() {
+		select {
+		case <-s.cclose:
+		default:
+			close(s.cclose)
+		}
+	}()
+
+	if s.proxy != nil {
+		return s.proxy.Close()
+	}
+	return nil
+}
+
+func (s *proxyTunnel) IsClosed() bool {
+	select {
+	case <-s.cclose:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *proxyTunnel) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *proxyTunnel) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
@@ -0,0 +1,37 @@
+package tunnel
+
+// The components available to the advanced tunnels, they register themselves to the gost registries.
+import (
+	_ "github.com/go-gost/x/connector/direct"
+	_ "github.com/go-gost/x/connector/forward"
+	_ "github.com/go-gost/x/connector/http"
+	_ "github.com/go-gost/x/connector/relay"
+	_ "github.com/go-gost/x/connector/sni"
+	_ "github.com/go-gost/x/connector/socks/v4"
+	_ "github.com/go-gost/x/connector/socks/v5"
+
+	_ "github.com/go-gost/x/dialer/direct"
+	_ "github.com/go-gost/x/dialer/mtls"
+	_ "github.com/go-gost/x/dialer/mws"
+	_ "github.com/go-gost/x/dialer/tcp"
+	_ "github.com/go-gost/x/dialer/udp"
+
+	_ "github.com/go-gost/x/handler/auto"
+	_ "github.com/go-gost/x/handler/file"
+	_ "github.com/go-gost/x/handler/forward/local"
+	_ "github.com/go-gost/x/handler/forward/remote"
+	_ "github.com/go-gost/x/handler/http"
+	_ "github.com/go-gost/x/handler/relay"
+	_ "github.com/go-gost/x/handler/sni"
+	_ "github.com/go-gost/x/handler/socks/v4"
+	_ "github.com/go-gost/x/handler/socks/v5"
+
+	_ "github.com/go-gost/x/listener/mtls"
+	_ "github.com/go-gost/x/listener/mws"
+	_ "github.com/go-gost/x/listener/rtcp"
+	_ "github.com/go-gost/x/listener/rudp"
+	_ "github.com/go-gost/x/listener/tcp"
+	_ "github.com/go-gost/x/listener/tls"
+	_ "github.com/go-gost/x/listener/udp"
+	_ "github.com/go-gost/x/listener/ws"
+)
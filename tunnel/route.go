@@ -0,0 +1,101 @@
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	cfg "github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/routing"
+)
+
+// ValidateRoutes checks the routes of an HTTP tunnel.
+func ValidateRoutes(routes []cfg.HTTPRoute) error {
+	for i, route := range routes {
+		if err := validateRoute(route); err != nil {
+			return fmt.Errorf("route %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateRoute(route cfg.HTTPRoute) error {
+	endpoint := strings.TrimSpace(route.Endpoint)
+	if endpoint == "" {
+		return errors.New("endpoint is required")
+	}
+	if _, err := net.ResolveTCPAddr("tcp", endpoint); err != nil {
+		return fmt.Errorf("invalid endpoint %q", route.Endpoint)
+	}
+	if path := strings.TrimSpace(route.Path); path != "" && !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path %q does not start with a '/'", path)
+	}
+
+	rule := routeRule(route)
+	if rule == "" {
+		return errors.New("host, path or header is required")
+	}
+	if strings.Contains(route.Host+route.Path+route.Header, "`") {
+		return errors.New("invalid character '`'")
+	}
+	_, err := routing.NewMatcher(rule)
+	return err
+}
+
+// routeRule converts the conditions of the route to a gost routing rule.
+func routeRule(route cfg.HTTPRoute) string {
+	var rules []string
+	if host := strings.TrimSpace(route.Host); host != "" {
+		rules = append(rules, fmt.Sprintf("Host(`%s`)", host))
+	}
+	if path := strings.TrimSpace(route.Path); path != "" {
+		rules = append(rules, fmt.Sprintf("PathPrefix(`%s`)", path))
+	}
+	if header := strings.TrimSpace(route.Header); header != "" {
+		if name, value, ok := strings.Cut(header, ":"); ok {
+			rules = append(rules, fmt.Sprintf("Header(`%s`, `%s`)", strings.TrimSpace(name), strings.TrimSpace(value)))
+		} else {
+			rules = append(rules, fmt.Sprintf("Header(`%s`)", header))
+		}
+	}
+	return strings.Join(rules, " && ")
+}
+
+// stripPrefix returns the URL rewrite removing the path prefix, "/api" and "/api/" become "/", "/api/v1" becomes "/v1".
+func stripPrefix(prefix string) *config.HTTPURLRewriteConfig {
+	prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return nil
+	}
+	return &config.HTTPURLRewriteConfig{
+		Match:       "^" + regexp.QuoteMeta(prefix) + "(?:/+(.*))?$",
+		Replacement: "/$1",
+	}
+}
+
+// routeNodes creates the forwarding nodes of the routes, the earlier routes have the higher priority.
+// The nodes are copied from the template node of the default endpoint, so they share its HTTP and TLS settings.
+func routeNodes(routes []cfg.HTTPRoute, tmpl *config.ForwardNodeConfig) []*config.ForwardNodeConfig {
+	var nodes []*config.ForwardNodeConfig
+	for i, route := range routes {
+		node := *tmpl
+		node.Addr = strings.TrimSpace(route.Endpoint)
+		node.Matcher = &config.NodeMatcherConfig{
+			Rule:     routeRule(route),
+			Priority: len(routes) - i,
+		}
+		if tmpl.HTTP != nil {
+			http := *tmpl.HTTP
+			http.RewriteURL = nil
+			if rewrite := stripPrefix(route.Path); route.StripPrefix && rewrite != nil {
+				http.RewriteURL = []config.HTTPURLRewriteConfig{*rewrite}
+			}
+			node.HTTP = &http
+		}
+		nodes = append(nodes, &node)
+	}
+	return nodes
+}
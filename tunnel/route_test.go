@@ -0,0 +1,167 @@
+package tunnel
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/go-gost/core/routing"
+	cfg "github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/x/config"
+	xrouting "github.com/go-gost/x/routing"
+)
+
+func TestRouteRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		route cfg.HTTPRoute
+		match []routing.Request
+		miss  []routing.Request
+	}{
+		{
+			name:  "path",
+			route: cfg.HTTPRoute{Path: "/api"},
+			match: []routing.Request{{Path: "/api"}, {Path: "/api/v1"}},
+			miss:  []routing.Request{{Path: "/"}, {Path: "/static/api"}},
+		},
+		{
+			name:  "host",
+			route: cfg.HTTPRoute{Host: " app.example.com "},
+			match: []routing.Request{{Host: "app.example.com", Path: "/"}, {Host: "app.example.com:8080", Path: "/"}},
+			miss:  []routing.Request{{Host: "example.com", Path: "/"}},
+		},
+		{
+			name:  "header name",
+			route: cfg.HTTPRoute{Header: "X-Debug"},
+			match: []routing.Request{{Path: "/", Header: http.Header{"X-Debug": {"1"}}}},
+			miss:  []routing.Request{{Path: "/", Header: http.Header{}}},
+		},
+		{
+			name:  "header value",
+			route: cfg.HTTPRoute{Header: "X-Env: staging"},
+			match: []routing.Request{{Path: "/", Header: http.Header{"X-Env": {"staging"}}}},
+			miss:  []routing.Request{{Path: "/", Header: http.Header{"X-Env": {"prod"}}}},
+		},
+		{
+			name:  "all",
+			route: cfg.HTTPRoute{Host: "app.example.com", Path: "/api", Header: "X-Env: staging"},
+			match: []routing.Request{{Host: "app.example.com", Path: "/api/v1", Header: http.Header{"X-Env": {"staging"}}}},
+			miss: []routing.Request{
+				{Host: "example.com", Path: "/api/v1", Header: http.Header{"X-Env": {"staging"}}},
+				{Host: "app.example.com", Path: "/", Header: http.Header{"X-Env": {"staging"}}},
+				{Host: "app.example.com", Path: "/api/v1", Header: http.Header{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := xrouting.NewMatcher(routeRule(tt.route))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, req := range tt.match {
+				if !m.Match(&req) {
+					t.Errorf("rule %s does not match %+v", routeRule(tt.route), req)
+				}
+			}
+			for _, req := range tt.miss {
+				if m.Match(&req) {
+					t.Errorf("rule %s matches %+v", routeRule(tt.route), req)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []cfg.HTTPRoute
+		ok     bool
+	}{
+		{name: "none", ok: true},
+		{name: "valid", routes: []cfg.HTTPRoute{{Path: "/api", Endpoint: "localhost:8080"}, {Host: "a.example.com", Endpoint: "127.0.0.1:5173"}}, ok: true},
+		{name: "no endpoint", routes: []cfg.HTTPRoute{{Path: "/api"}}},
+		{name: "no condition", routes: []cfg.HTTPRoute{{Endpoint: "localhost:8080"}}},
+		{name: "relative path", routes: []cfg.HTTPRoute{{Path: "api", Endpoint: "localhost:8080"}}},
+		{name: "backquote", routes: []cfg.HTTPRoute{{Path: "/api`) || Path(`/", Endpoint: "localhost:8080"}}},
+		{name: "second invalid", routes: []cfg.HTTPRoute{{Path: "/api", Endpoint: "localhost:8080"}, {Endpoint: "localhost:8080"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRoutes(tt.routes); (err == nil) != tt.ok {
+				t.Errorf("ValidateRoutes() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestStripPrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		path   string
+		want   string
+	}{
+		{prefix: "/api", path: "/api", want: "/"},
+		{prefix: "/api", path: "/api/", want: "/"},
+		{prefix: "/api", path: "/api/v1", want: "/v1"},
+		{prefix: "/api/", path: "/api/v1/users", want: "/v1/users"},
+		{prefix: "/api", path: "/api//v1", want: "/v1"},
+		{prefix: "/api", path: "/apiv1", want: "/apiv1"},
+		{prefix: "/a.b", path: "/axb/c", want: "/axb/c"},
+		{prefix: "/a.b", path: "/a.b/c", want: "/c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix+" "+tt.path, func(t *testing.T) {
+			rewrite := stripPrefix(tt.prefix)
+			if rewrite == nil {
+				t.Fatal("no rewrite")
+			}
+			got := regexp.MustCompile(rewrite.Match).ReplaceAllString(tt.path, rewrite.Replacement)
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, prefix := range []string{"", "/", " / "} {
+		if rewrite := stripPrefix(prefix); rewrite != nil {
+			t.Errorf("stripPrefix(%q) = %+v, want nil", prefix, rewrite)
+		}
+	}
+}
+
+func TestRouteNodes(t *testing.T) {
+	tmpl := &config.ForwardNodeConfig{
+		Name: "tunnel",
+		Addr: "localhost:8000",
+		HTTP: &config.HTTPNodeConfig{
+			Host:       "example.com",
+			RewriteURL: []config.HTTPURLRewriteConfig{{Match: "^/$", Replacement: "/index.html"}},
+		},
+	}
+	routes := []cfg.HTTPRoute{
+		{Path: "/api", StripPrefix: true, Endpoint: " localhost:8080 "},
+		{Path: "/static", Endpoint: "localhost:5173"},
+	}
+
+	nodes := routeNodes(routes, tmpl)
+	if len(nodes) != 2 {
+		t.Fatalf("got %d nodes, want 2", len(nodes))
+	}
+	if nodes[0].Addr != "localhost:8080" || nodes[0].Matcher.Priority <= nodes[1].Matcher.Priority {
+		t.Errorf("got node %s with priority %d, the next %d", nodes[0].Addr, nodes[0].Matcher.Priority, nodes[1].Matcher.Priority)
+	}
+	if len(nodes[0].HTTP.RewriteURL) != 1 || nodes[0].HTTP.RewriteURL[0] != *stripPrefix("/api") {
+		t.Errorf("got rewrite %+v", nodes[0].HTTP.RewriteURL)
+	}
+	if nodes[1].HTTP.RewriteURL != nil || nodes[1].HTTP.Host != "example.com" {
+		t.Errorf("got node %+v", nodes[1].HTTP)
+	}
+	if len(tmpl.HTTP.RewriteURL) != 1 {
+		t.Errorf("template node is changed")
+	}
+}
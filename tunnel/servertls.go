@@ -0,0 +1,219 @@
+package tunnel
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-gost/core/dialer"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/x/registry"
+)
+
+func init() {
+	for _, t := range Transports {
+		if newDialer := registry.DialerRegistry().Get(t); newDialer != nil {
+			registry.DialerRegistry().Register(serverDialer(t), newServerDialer(newDialer))
+		}
+	}
+}
+
+// serverDialer returns the name of the dialer to the tunnel server for the transport,
+// it is the dialer of the transport with the verification of the ServerTLS.
+func serverDialer(transport string) string {
+	return "gost.plus-" + transport
+}
+
+// newServerDialer wraps the dialer constructor, the TLS it is created with is verified by verifyServer.
+func newServerDialer(newDialer registry.NewDialer) registry.NewDialer {
+	return func(opts ...dialer.Option) dialer.Dialer {
+		var options dialer.Options
+		for _, opt := range opts {
+			opt(&options)
+		}
+
+		if c := options.TLSConfig; c != nil {
+			roots, serverName := c.RootCAs, c.ServerName
+			c = c.Clone()
+			// The certificate is verified by VerifyConnection instead, which also accepts the pinned certificates.
+			c.InsecureSkipVerify = true
+			c.VerifyConnection = func(state tls.ConnectionState) error {
+				return verifyServer(state, roots, serverName)
+			}
+			options.TLSConfig = c
+		}
+
+		return newDialer(
+			dialer.AuthOption(options.Auth),
+			dialer.TLSConfigOption(options.TLSConfig),
+			dialer.LoggerOption(options.Logger),
+			dialer.ProxyProtocolOption(options.ProxyProtocol),
+		)
+	}
+}
+
+// verifyServer verifies the certificate of the tunnel server. With a CA the chain is always verified by it,
+// the pins are an extra check then. Without a CA the leaf certificate must be pinned if there are pins,
+// otherwise it is verified by the system roots. Only the leaf is pinned, since the handshake only proves
+// that the server holds its key, the other certificates can be appended by anyone.
+func verifyServer(state tls.ConnectionState, roots *x509.CertPool, serverName string) error {
+	certs := state.PeerCertificates
+	if len(certs) == 0 {
+		return errors.New("tunnel server: no certificate")
+	}
+	leaf := certs[0]
+	pins := ServerTLSOf().Pins
+
+	if roots != nil || len(pins) == 0 {
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			DNSName:       serverName,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		if _, err := leaf.Verify(opts); err != nil {
+			if roots != nil {
+				// pinning does not help, the certificate must be issued by the CA.
+				return fmt.Errorf("tunnel server %s: %w", ServerAddr(), err)
+			}
+			return untrusted(&UntrustedCertError{
+				Addr:        ServerAddr(),
+				Subject:     leaf.Subject.String(),
+				Fingerprint: Fingerprint(leaf),
+				Err:         err,
+			})
+		}
+	}
+
+	if len(pins) > 0 && !slices.Contains(pins, Fingerprint(leaf)) {
+		return untrusted(&UntrustedCertError{
+			Addr:        ServerAddr(),
+			Subject:     leaf.Subject.String(),
+			Fingerprint: Fingerprint(leaf),
+			Changed:     true,
+		})
+	}
+	return nil
+}
+
+// ServerTLSOf returns the ServerTLS in the settings, or the zero ServerTLS if it is not set.
+func ServerTLSOf() config.ServerTLS {
+	if settings := config.Get().Settings; settings != nil && settings.ServerTLS != nil {
+		return *settings.ServerTLS
+	}
+	return config.ServerTLS{}
+}
+
+// ValidateServerTLS checks the ServerTLS, the PEM files must be readable and the pins must be SHA-256 fingerprints.
+func ValidateServerTLS(t *config.ServerTLS) error {
+	if t == nil {
+		return nil
+	}
+	if _, err := tlsConfig(&config.TLS{CA: t.CA, Cert: t.Cert, Key: t.Key}); err != nil {
+		return err
+	}
+	for _, pin := range t.Pins {
+		if _, err := ParsePin(pin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fingerprint returns the SHA-256 fingerprint of the certificate in hex.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParsePin parses the SHA-256 fingerprint, the bytes can be separated by colons, e.g. AB:CD:...
+func ParsePin(s string) (string, error) {
+	pin := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), ":", ""))
+	if b, err := hex.DecodeString(pin); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid pin %q, a SHA-256 fingerprint is required", s)
+	}
+	return pin, nil
+}
+
+// FormatFingerprint returns the fingerprint with the bytes separated by colons in upper case, as it is shown by browsers.
+func FormatFingerprint(fingerprint string) string {
+	var b strings.Builder
+	for i := 0; i < len(fingerprint); i += 2 {
+		if i > 0 {
+			b.WriteByte(':')
+		}
+		b.WriteString(strings.ToUpper(fingerprint[i:min(i+2, len(fingerprint))]))
+	}
+	return b.String()
+}
+
+// TrustServerCert pins the certificate of the tunnel server and writes the config,
+// the tunnels must be reloaded to connect with it. If replace is true, the certificate replaces the pinned ones,
+// so that the key of a changed certificate is no longer trusted.
+func TrustServerCert(fingerprint string, replace bool) error {
+	pin, err := ParsePin(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.Get()
+	if cfg.Settings == nil {
+		cfg.Settings = &config.Settings{}
+	}
+	serverTLS := ServerTLSOf()
+	switch {
+	case replace:
+		serverTLS.Pins = []string{pin}
+	case !slices.Contains(serverTLS.Pins, pin):
+		serverTLS.Pins = append(slices.Clone(serverTLS.Pins), pin)
+	}
+	cfg.Settings.ServerTLS = &serverTLS
+	config.Set(cfg)
+	return cfg.Write()
+}
+
+// UntrustedCertError is the certificate of the tunnel server which is not trusted,
+// it can be trusted by pinning the fingerprint in the ServerTLS.
+type UntrustedCertError struct {
+	Addr        string
+	Subject     string
+	Fingerprint string
+	// Changed tells that the certificate is not one of the pinned certificates, trusting it replaces the pins.
+	Changed bool
+	Err     error
+}
+
+func (e *UntrustedCertError) Error() string {
+	if e.Changed {
+		return fmt.Sprintf("certificate of the tunnel server %s is not pinned, SHA-256 fingerprint %s, the certificate may have changed",
+			e.Addr, FormatFingerprint(e.Fingerprint))
+	}
+	return fmt.Sprintf("certificate of the tunnel server %s is not trusted: %v, set the CA or pin the SHA-256 fingerprint %s",
+		e.Addr, e.Err, FormatFingerprint(e.Fingerprint))
+}
+
+func (e *UntrustedCertError) Unwrap() error {
+	return e.Err
+}
+
+var untrustedCerts = make(chan *UntrustedCertError, 16)
+
+// UntrustedCert returns the untrusted certificates of the tunnel server, the user can be asked to trust them.
+func UntrustedCert() <-chan *UntrustedCertError {
+	return untrustedCerts
+}
+
+func untrusted(err *UntrustedCertError) error {
+	select {
+	case untrustedCerts <- err:
+	default:
+	}
+	return err
+}
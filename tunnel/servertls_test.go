@@ -0,0 +1,176 @@
+package tunnel
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-gost/gost.plus/config"
+)
+
+func TestParsePin(t *testing.T) {
+	pin := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		s    string
+		want string
+		err  bool
+	}{
+		{s: pin, want: pin},
+		{s: " " + strings.ToUpper(pin) + "\n", want: pin},
+		{s: FormatFingerprint(pin), want: pin},
+		{s: "", err: true},
+		{s: pin[:62], err: true},
+		{s: pin + "ab", err: true},
+		{s: pin[:62] + "zz", err: true},
+		{s: "sha256:" + pin, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParsePin(tt.s)
+			if (err != nil) != tt.err {
+				t.Fatalf("got %s, %v, want error %v", got, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFingerprint(t *testing.T) {
+	if got := FormatFingerprint("0aff1b"); got != "0A:FF:1B" {
+		t.Errorf("got %s", got)
+	}
+	if got := FormatFingerprint(""); got != "" {
+		t.Errorf("got %s", got)
+	}
+}
+
+// testCert creates a certificate for the DNS names signed by the parent, it is self-signed if parent is nil.
+func testCert(t *testing.T, cn string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, names ...string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              names,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestVerifyServer(t *testing.T) {
+	const serverName = "tunnel.example.com"
+
+	ca, caKey := testCert(t, "CA", true, nil, nil)
+	leaf, _ := testCert(t, serverName, false, ca, caKey, serverName)
+	other, _ := testCert(t, "other", false, ca, caKey, "other.example.com")
+	self, _ := testCert(t, serverName, false, nil, nil, serverName)
+	attacker, _ := testCert(t, serverName, false, nil, nil, serverName)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	prev := config.Get()
+	t.Cleanup(func() { config.Set(prev) })
+
+	tests := []struct {
+		name  string
+		certs []*x509.Certificate
+		roots *x509.CertPool
+		pins  []*x509.Certificate
+		// ok is true if the certificate is trusted, otherwise untrusted tells whether the user can trust it,
+		// and changed whether it replaces the pins.
+		ok        bool
+		untrusted bool
+		changed   bool
+	}{
+		{name: "no certificate"},
+		{name: "CA", certs: []*x509.Certificate{leaf}, roots: roots, ok: true},
+		{name: "CA wrong name", certs: []*x509.Certificate{other}, roots: roots},
+		{name: "CA self-signed", certs: []*x509.Certificate{self}, roots: roots},
+		{name: "CA self-signed pinned", certs: []*x509.Certificate{self}, roots: roots, pins: []*x509.Certificate{self}},
+		{name: "CA pinned", certs: []*x509.Certificate{leaf}, roots: roots, pins: []*x509.Certificate{self, leaf}, ok: true},
+		{name: "CA not pinned", certs: []*x509.Certificate{leaf}, roots: roots, pins: []*x509.Certificate{self}, untrusted: true, changed: true},
+		{name: "system roots", certs: []*x509.Certificate{self}, untrusted: true},
+		{name: "pinned", certs: []*x509.Certificate{self}, pins: []*x509.Certificate{self}, ok: true},
+		{name: "pin changed", certs: []*x509.Certificate{attacker}, pins: []*x509.Certificate{self}, untrusted: true, changed: true},
+		{name: "pinned certificate in chain", certs: []*x509.Certificate{attacker, self}, pins: []*x509.Certificate{self}, untrusted: true, changed: true},
+		{name: "pinned CA in chain", certs: []*x509.Certificate{attacker, ca}, pins: []*x509.Certificate{ca}, untrusted: true, changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pins []string
+			for _, cert := range tt.pins {
+				pins = append(pins, Fingerprint(cert))
+			}
+			config.Set(&config.Config{
+				Settings: &config.Settings{
+					Server:    serverName,
+					ServerTLS: &config.ServerTLS{Pins: pins},
+				},
+			})
+
+			err := verifyServer(tls.ConnectionState{PeerCertificates: tt.certs}, tt.roots, serverName)
+			if (err == nil) != tt.ok {
+				t.Fatalf("got error %v, want ok %v", err, tt.ok)
+			}
+			if tt.ok {
+				return
+			}
+
+			var e *UntrustedCertError
+			if errors.As(err, &e) != tt.untrusted {
+				t.Fatalf("got error %v, want untrusted %v", err, tt.untrusted)
+			}
+			if !tt.untrusted {
+				return
+			}
+			if e.Changed != tt.changed {
+				t.Errorf("got changed %v, want %v", e.Changed, tt.changed)
+			}
+			if e.Fingerprint != Fingerprint(tt.certs[0]) {
+				t.Errorf("got fingerprint of %s, want the leaf", e.Subject)
+			}
+			select {
+			case v := <-UntrustedCert():
+				if v != e {
+					t.Errorf("got notified %v, want %v", v, e)
+				}
+			default:
+				t.Error("untrusted certificate is not notified")
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package tunnel
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+)
+
+const (
+	// MinRetryDelay is the delay before the first restart of a failed tunnel.
+	MinRetryDelay = time.Second
+	// MaxRetryDelay is the upper limit of the exponential backoff.
+	MaxRetryDelay = 5 * time.Minute
+	// RetryResetAfter is how long a restarted tunnel must keep running before the backoff is reset.
+	RetryResetAfter = time.Minute
+)
+
+// RetryState describes the restarts of a failed tunnel.
+type RetryState struct {
+	// Attempts is the number of restarts since the tunnel last failed.
+	Attempts int
+	// Err is the last error of the tunnel.
+	Err error
+	// Next is the time of the next restart, it is zero while the restarted tunnel is running.
+	Next time.Time
+	// RestartedAt is the time of the last restart.
+	RestartedAt time.Time
+}
+
+// Retrying reports whether the tunnel is waiting for the next restart.
+func (s RetryState) Retrying() bool {
+	return !s.Next.IsZero()
+}
+
+// Supervisor restarts the failed tunnels with exponential backoff and jitter.
+// The tunnels closed by the user are never restarted.
+type Supervisor struct {
+	states   map[string]*RetryState
+	restarts map[string]int
+	mu       sync.Mutex
+}
+
+func NewSupervisor() *Supervisor {
+	return &Supervisor{
+		states:   make(map[string]*RetryState),
+		restarts: make(map[string]int),
+	}
+}
+
+// Check restarts the tunnel by calling restart if it has failed and the backoff delay has elapsed.
+func (p *Supervisor) Check(t Tunnel, restart func(t Tunnel) (Tunnel, error)) {
+	if t == nil || !p.due(t) {
+		return
+	}
+
+	log := logger.Default().WithFields(map[string]any{
+		"kind":    "supervisor",
+		"service": t.Name(),
+	})
+
+	state, _ := p.State(t.ID())
+	log.Infof("restarting %s (attempt %d): %v", t.Name(), state.Attempts, state.Err)
+
+	if _, err := restart(t); err != nil {
+		log.Error(err)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if state := p.states[t.ID()]; state != nil {
+			state.Err = err
+			state.Next = time.Now().Add(backoff(state.Attempts))
+		}
+	}
+}
+
+// due updates the retry state of the tunnel and reports whether it should be restarted now.
+func (p *Supervisor) due(t Tunnel) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := t.ID()
+	if t.IsClosed() {
+		delete(p.states, id)
+		return false
+	}
+
+	state := p.states[id]
+
+	err := t.Err()
+	if err == nil {
+		if state != nil && state.Next.IsZero() && time.Since(state.RestartedAt) >= RetryResetAfter {
+			delete(p.states, id)
+		}
+		return false
+	}
+
+	now := time.Now()
+	if state == nil {
+		state = &RetryState{}
+		p.states[id] = state
+	}
+	if state.Next.IsZero() {
+		state.Err = err
+		state.Next = now.Add(backoff(state.Attempts))
+		return false
+	}
+	if now.Before(state.Next) {
+		return false
+	}
+
+	state.Attempts++
+	state.RestartedAt = now
+	p.restarts[id]++
+	state.Next = time.Time{}
+
+	return true
+}
+
+// State returns the retry state of the tunnel, ok is false if the tunnel has not failed recently.
+func (p *Supervisor) State(id string) (state RetryState, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s := p.states[id]; s != nil {
+		return *s, true
+	}
+	return
+}
+
+// Restarts returns the total number of restarts of the tunnel.
+func (p *Supervisor) Restarts(id string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.restarts[id]
+}
+
+// backoff returns the delay before the next restart, half of it is randomized to spread the reconnections.
+func backoff(attempts int) time.Duration {
+	d := MaxRetryDelay
+	if attempts < 16 {
+		d = min(MinRetryDelay<<attempts, MaxRetryDelay)
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+var (
+	supervisor = NewSupervisor()
+)
+
+// Supervise restarts the failed tunnels, it is called periodically by the runner.
+func Supervise() {
+	for i := 0; i < Count(); i++ {
+		supervisor.Check(GetIndex(i), restart)
+	}
+}
+
+// Retry returns the retry state of the tunnel, see Supervisor.State.
+func Retry(id string) (RetryState, bool) {
+	return supervisor.State(id)
+}
+
+// Restarts returns the total number of restarts of the tunnel, see Supervisor.Restarts.
+func Restarts(id string) int {
+	return supervisor.Restarts(id)
+}
+
+// restart replaces the tunnel with a new one created from the same options and runs it.
+func restart(tun Tunnel) (Tunnel, error) {
+	tun.Close()
+
+	opts := tun.Options()
+	opts.Stats = tun.Stats()
+	t := NewTunnel(tun.Type(), opts)
+	if t == nil {
+		return nil, nil
+	}
+	Set(t)
+
+	return t, t.Run()
+}
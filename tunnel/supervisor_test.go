@@ -0,0 +1,136 @@
+package tunnel
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	xlogger "github.com/go-gost/x/logger"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		min, max time.Duration
+	}{
+		{attempts: 0, min: 500 * time.Millisecond, max: time.Second},
+		{attempts: 1, min: time.Second, max: 2 * time.Second},
+		{attempts: 3, min: 4 * time.Second, max: 8 * time.Second},
+		{attempts: 8, min: 128 * time.Second, max: 256 * time.Second},
+		{attempts: 9, min: MaxRetryDelay / 2, max: MaxRetryDelay},
+		{attempts: 16, min: MaxRetryDelay / 2, max: MaxRetryDelay},
+		{attempts: 100, min: MaxRetryDelay / 2, max: MaxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if d := backoff(tt.attempts); d < tt.min || d > tt.max {
+				t.Fatalf("backoff(%d) = %s, want in [%s, %s]", tt.attempts, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+// fakeTunnel is a tunnel whose error and state are set by the test.
+type fakeTunnel struct {
+	Tunnel
+	err    error
+	closed bool
+}
+
+func (t *fakeTunnel) ID() string     { return "fake" }
+func (t *fakeTunnel) Name() string   { return "fake" }
+func (t *fakeTunnel) Err() error     { return t.err }
+func (t *fakeTunnel) IsClosed() bool { return t.closed }
+
+func TestSupervisor(t *testing.T) {
+	logger.SetDefault(xlogger.NewLogger(xlogger.OutputOption(io.Discard)))
+
+	p := NewSupervisor()
+	tun := &fakeTunnel{}
+
+	var restarts int
+	var restartErr error
+	restart := func(t Tunnel) (Tunnel, error) {
+		restarts++
+		return t, restartErr
+	}
+	// elapse makes the scheduled restart due.
+	elapse := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if state := p.states[tun.ID()]; state != nil && !state.Next.IsZero() {
+			state.Next = time.Now().Add(-time.Millisecond)
+		}
+	}
+
+	p.Check(tun, restart)
+	if _, ok := p.State(tun.ID()); ok || restarts != 0 {
+		t.Fatal("running tunnel is supervised")
+	}
+
+	// the failed tunnel is restarted after the backoff delay.
+	tun.err = errors.New("failed")
+	p.Check(tun, restart)
+	state, ok := p.State(tun.ID())
+	if !ok || !state.Retrying() || state.Attempts != 0 || restarts != 0 {
+		t.Fatalf("got state %+v, %d restarts", state, restarts)
+	}
+	if d := time.Until(state.Next); d > MinRetryDelay {
+		t.Errorf("first restart in %s, want at most %s", d, MinRetryDelay)
+	}
+	p.Check(tun, restart)
+	if restarts != 0 {
+		t.Fatal("restarted before the delay")
+	}
+
+	elapse()
+	restartErr = errors.New("still failing")
+	p.Check(tun, restart)
+	state, _ = p.State(tun.ID())
+	if restarts != 1 || state.Attempts != 1 || p.Restarts(tun.ID()) != 1 {
+		t.Fatalf("got state %+v, %d restarts", state, restarts)
+	}
+	// the failed restart schedules the next one with a longer delay.
+	if !state.Retrying() || state.Err != restartErr || time.Until(state.Next) < MinRetryDelay {
+		t.Errorf("got state %+v after a failed restart", state)
+	}
+
+	elapse()
+	restartErr = nil
+	tun.err = nil
+	p.Check(tun, restart)
+	if restarts != 1 {
+		t.Fatal("recovered tunnel is restarted")
+	}
+	if _, ok := p.State(tun.ID()); !ok {
+		t.Fatal("state is reset before the tunnel has kept running")
+	}
+
+	// the backoff is reset once the tunnel keeps running.
+	p.mu.Lock()
+	p.states[tun.ID()].Next = time.Time{}
+	p.states[tun.ID()].RestartedAt = time.Now().Add(-RetryResetAfter)
+	p.mu.Unlock()
+	p.Check(tun, restart)
+	if _, ok := p.State(tun.ID()); ok {
+		t.Error("state is not reset")
+	}
+	if n := p.Restarts(tun.ID()); n != 1 {
+		t.Errorf("got %d total restarts, want 1", n)
+	}
+
+	// the tunnel closed by the user is never restarted.
+	tun.err = errors.New("failed")
+	p.Check(tun, restart)
+	tun.closed = true
+	elapse()
+	p.Check(tun, restart)
+	if _, ok := p.State(tun.ID()); ok || restarts != 1 {
+		t.Errorf("closed tunnel is supervised, %d restarts", restarts)
+	}
+
+	p.Check(nil, restart)
+}
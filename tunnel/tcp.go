@@ -1,6 +1,7 @@
 package tunnel
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 	xchain "github.com/go-gost/x/chain"
 	"github.com/go-gost/x/config"
 	chain_parser "github.com/go-gost/x/config/parsing/chain"
+	selector_parser "github.com/go-gost/x/config/parsing/selector"
 	"github.com/go-gost/x/handler/forward/remote"
 	"github.com/go-gost/x/hop"
 	"github.com/go-gost/x/listener/rtcp"
@@ -31,6 +33,7 @@ type tcpTunnel struct {
 	opts     Options
 	config   *config.Config
 	forward  service.Service
+	backends *backendGroup
 	favorite atomic.Bool
 	stats    cfg.ServiceStats
 
@@ -90,7 +93,7 @@ func (s *tcpTunnel) Endpoint() string {
 }
 
 func (s *tcpTunnel) Entrypoint() string {
-	return fmt.Sprintf("%s.%s", s.endpoint, EndpointAddr)
+	return fmt.Sprintf("%s.%s", s.endpoint, EndpointAddr())
 }
 
 func (s *tcpTunnel) Options() Options {
@@ -116,19 +119,20 @@ func (s *tcpTunnel) init() error {
 			Type:  "rtcp",
 			Chain: s.opts.Name,
 		},
-		Forwarder: &config.ForwarderConfig{
-			Nodes: []*config.ForwardNodeConfig{
-				{
-					Name: s.opts.Name,
-					Addr: s.opts.Endpoint,
-				},
-			},
-		},
+		Forwarder: &config.ForwarderConfig{},
+	}
+	endpoints := Endpoints(s.opts.Endpoint)
+	for _, endpoint := range endpoints {
+		rtcp.Forwarder.Nodes = append(rtcp.Forwarder.Nodes, &config.ForwardNodeConfig{
+			Name: s.opts.Name,
+			Addr: endpoint,
+		})
 	}
+	rtcp.Forwarder.Selector = selectorConfig(s.opts.Strategy, len(endpoints))
 
 	s.config = &config.Config{
 		Services: []*config.ServiceConfig{rtcp},
-		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name)},
+		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name, s.opts.Transport, s.opts.Proxies)},
 	}
 	return nil
 }
@@ -172,22 +176,31 @@ func (s *tcpTunnel) Run() (err error) {
 			return
 		}
 
+		var nodes []*chain.Node
+		for _, node := range cfg.Forwarder.Nodes {
+			nodes = append(nodes, chain.NewNode(node.Name, node.Addr))
+		}
+		backends := newBackendGroup(nodes)
+
 		handlerLogger := log.WithFields(map[string]any{"kind": "handler", "handler": "rtcp"})
 		h := remote.NewHandler(
-			handler.RouterOption(xchain.NewRouter(chain.LoggerRouterOption(handlerLogger))),
+			handler.RouterOption(backends.Router(xchain.NewRouter(chain.LoggerRouterOption(handlerLogger)))),
 			handler.LoggerOption(handlerLogger),
 		)
 		if err = h.Init(mdx.NewMetadata(cfg.Handler.Metadata)); err != nil {
 			return
 		}
 
-		node := cfg.Forwarder.Nodes[0]
 		if forwarder, ok := h.(handler.Forwarder); ok {
 			forwarder.Forward(hop.NewHop(
-				hop.NodeOption(chain.NewNode(node.Name, node.Addr)),
+				hop.NodeOption(nodes...),
+				hop.SelectorOption(selector_parser.ParseNodeSelector(cfg.Forwarder.Selector)),
 				hop.LoggerOption(log.WithFields(map[string]any{"kind": "hop"})),
 			))
 		}
+		s.mu.Lock()
+		s.backends = backends
+		s.mu.Unlock()
 		s.forward = xservice.NewService(s.opts.Name, ln, h,
 			xservice.LoggerOption(log),
 			xservice.StatsOption(stats),
@@ -208,6 +221,19 @@ func (s *tcpTunnel) Status() *xservice.Status {
 	return nil
 }
 
+func (s *tcpTunnel) Backends() []BackendStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.backends.Stats()
+}
+
+func (s *tcpTunnel) checkBackends(ctx context.Context) error {
+	s.mu.RLock()
+	backends := s.backends
+	s.mu.RUnlock()
+	return backends.Check(ctx)
+}
+
 func (s *tcpTunnel) Stats() cfg.ServiceStats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -0,0 +1,122 @@
+package tunnel
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/go-gost/core/chain"
+	cfg "github.com/go-gost/gost.plus/config"
+)
+
+// ValidateTLS checks the TLS settings of an HTTP tunnel, the PEM files must be readable.
+func ValidateTLS(t *cfg.TLS) error {
+	_, err := tlsConfig(t)
+	return err
+}
+
+// tlsConfig loads the TLS settings to the endpoints.
+func tlsConfig(t *cfg.TLS) (*tls.Config, error) {
+	config := &tls.Config{}
+	if t == nil {
+		return config, nil
+	}
+
+	config.ServerName = strings.TrimSpace(t.ServerName)
+	config.InsecureSkipVerify = t.Insecure
+
+	if t.CA != "" {
+		b, err := os.ReadFile(t.CA)
+		if err != nil {
+			return nil, fmt.Errorf("CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("CA: no PEM certificate found in %s", t.CA)
+		}
+		config.RootCAs = pool
+	}
+
+	if t.Cert != "" || t.Key != "" {
+		if t.Cert == "" || t.Key == "" {
+			return nil, errors.New("client certificate: both the certificate and the key are required")
+		}
+		cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// BackendErrorer is implemented by the tunnels which report the failed TLS handshakes with their endpoints.
+// The error does not stop the tunnel, so it is kept apart from Err and the tunnel is not restarted by the supervisor.
+type BackendErrorer interface {
+	// BackendErr returns the error of the last handshake, it is nil after a handshake succeeds.
+	BackendErr() error
+}
+
+// TLSError is the failed TLS handshake with an endpoint, it tells what to change in the TLS settings.
+type TLSError struct {
+	Addr string
+	Err  error
+}
+
+func (e *TLSError) Error() string {
+	var (
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	hint := ""
+	switch {
+	case errors.As(e.Err, &authorityErr):
+		hint = ", set the CA of the endpoint certificate or skip the verification"
+	case errors.As(e.Err, &hostnameErr):
+		hint = ", set the server name to a name of the endpoint certificate"
+	case errors.As(e.Err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		hint = ", renew the endpoint certificate"
+	}
+	return fmt.Sprintf("TLS handshake with %s: %v%s", e.Addr, e.Err, hint)
+}
+
+func (e *TLSError) Unwrap() error {
+	return e.Err
+}
+
+// tlsRouter connects to the endpoints with TLS. It takes the place of the TLS settings of the forwarding nodes,
+// which have no CA or client certificate.
+type tlsRouter struct {
+	chain.Router
+	config *tls.Config
+	// handshake is called with the result of each handshake.
+	handshake func(err error)
+}
+
+func (r *tlsRouter) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := r.Router.Dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	config := r.config.Clone()
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(address)
+	}
+	tc := tls.Client(conn, config)
+	if err := tc.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		err = &TLSError{Addr: address, Err: err}
+		r.handshake(err)
+		return nil, err
+	}
+	r.handshake(nil)
+	return tc, nil
+}
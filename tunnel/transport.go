@@ -0,0 +1,107 @@
+package tunnel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/gost.plus/config"
+	xconfig "github.com/go-gost/x/config"
+	"github.com/go-gost/x/registry"
+
+	// The dialers of the Transports.
+	_ "github.com/go-gost/x/dialer/grpc"
+	_ "github.com/go-gost/x/dialer/http2/h2"
+	_ "github.com/go-gost/x/dialer/kcp"
+	_ "github.com/go-gost/x/dialer/quic"
+	_ "github.com/go-gost/x/dialer/tls"
+	_ "github.com/go-gost/x/dialer/ws"
+)
+
+// The transports to the tunnel server.
+const (
+	TransportWSS  = "wss"
+	TransportWS   = "ws"
+	TransportH2   = "h2"
+	TransportGRPC = "grpc"
+	TransportQUIC = "quic"
+	TransportKCP  = "kcp"
+	TransportTLS  = "tls"
+
+	DefaultTransport = TransportWSS
+)
+
+// Transports are the transports which can be chosen, the tunnel server must listen on the same transport.
+var Transports = []string{
+	TransportWSS,
+	TransportWS,
+	TransportH2,
+	TransportGRPC,
+	TransportQUIC,
+	TransportKCP,
+	TransportTLS,
+}
+
+// ValidateTransport checks the transport, the dialer of the type must be compiled in.
+// A nil transport or an empty type is valid, the transport in the settings is used for it.
+func ValidateTransport(t *config.Transport) error {
+	if t == nil || t.Type == "" {
+		return nil
+	}
+	if !slices.Contains(Transports, t.Type) {
+		return fmt.Errorf("unknown transport %s, available transports are %s", t.Type, strings.Join(Transports, ", "))
+	}
+	if !registry.DialerRegistry().IsRegistered(t.Type) {
+		return fmt.Errorf("transport %s is not compiled in", t.Type)
+	}
+	if t.Path != "" && !strings.HasPrefix(t.Path, "/") {
+		return fmt.Errorf("path %q does not start with a '/'", t.Path)
+	}
+	return nil
+}
+
+// TransportOf returns the transport in use, t falls back to the transport in the settings and then the DefaultTransport.
+func TransportOf(t *config.Transport) config.Transport {
+	if t != nil && t.Type != "" {
+		return *t
+	}
+	if settings := config.Get().Settings; settings != nil && settings.Transport != nil && settings.Transport.Type != "" {
+		return *settings.Transport
+	}
+	return config.Transport{Type: DefaultTransport}
+}
+
+// DialerConfig returns the dialer to the tunnel server for the transport, see TransportOf.
+// The TLS to the tunnel server is set by the ServerTLS in the settings.
+func DialerConfig(transport *config.Transport) *xconfig.DialerConfig {
+	t := TransportOf(transport)
+	if err := ValidateTransport(&t); err != nil {
+		logger.Default().Warnf("%v, fall back to %s", err, DefaultTransport)
+		t = config.Transport{Type: DefaultTransport}
+	}
+
+	var md map[string]any
+	if t.Path != "" || len(t.Metadata) > 0 {
+		md = make(map[string]any)
+		for k, v := range t.Metadata {
+			md[k] = v
+		}
+		if t.Path != "" {
+			md["path"] = t.Path
+		}
+	}
+
+	serverTLS := ServerTLSOf()
+	return &xconfig.DialerConfig{
+		Type: serverDialer(t.Type),
+		TLS: &xconfig.TLSConfig{
+			Secure:     true,
+			ServerName: ServerName(),
+			CAFile:     serverTLS.CA,
+			CertFile:   serverTLS.Cert,
+			KeyFile:    serverTLS.Key,
+		},
+		Metadata: md,
+	}
+}
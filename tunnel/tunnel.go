@@ -1,22 +1,29 @@
 package tunnel
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/observer/stats"
 	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/tunnel/history"
+	"github.com/go-gost/gost.plus/tunnel/inspector"
 	xconfig "github.com/go-gost/x/config"
 	_ "github.com/go-gost/x/connector/tunnel"
-	_ "github.com/go-gost/x/dialer/ws"
 	xservice "github.com/go-gost/x/service"
 )
 
 const (
-	EndpointAddr = "gost.plus"
-	ServerName   = "tunnel.gost.plus"
-	ServerAddr   = ServerName + ":443"
+	DefaultEndpointAddr = "gost.plus"
+	DefaultServerName   = "tunnel.gost.plus"
+	DefaultServerPort   = "443"
 )
 
 const (
@@ -24,10 +31,15 @@ const (
 	HTTPTunnel = "http"
 	TCPTunnel  = "tcp"
 	UDPTunnel  = "udp"
+	// ProxyTunnel exposes a SOCKS5 or HTTP proxy running in the client.
+	ProxyTunnel = "proxy"
+	// AdvancedTunnel runs the services of a raw gost config.
+	AdvancedTunnel = "advanced"
 )
 
 var (
 	ErrTunnelClosed = errors.New("tunnel closed")
+	ErrLoopbackAddr = errors.New("the address must be a loopback address")
 )
 
 type Options struct {
@@ -38,8 +50,23 @@ type Options struct {
 	Username  string
 	Password  string
 	EnableTLS bool
+	// TLS of the HTTP tunnel to the endpoints, the certificates are verified by the system roots if it is nil.
+	TLS       *config.TLS
 	Keepalive bool
 	TTL       int
+	// Strategy selects the backend if the endpoint has multiple comma-separated addresses.
+	Strategy string
+	// Routes of the HTTP tunnel, the requests not matching any route are forwarded to the endpoint.
+	Routes []config.HTTPRoute
+	// RequestHeaders and ResponseHeaders are set or removed by the HTTP tunnel.
+	RequestHeaders  []config.HTTPHeader
+	ResponseHeaders []config.HTTPHeader
+	// Transport to the tunnel server, the one in the settings is used if it is nil.
+	Transport *config.Transport
+	// Proxies are the upstream proxies to the tunnel server, the ones in the settings are used if it is empty.
+	Proxies []config.Proxy
+	// Config is the raw gost config (services, chains, etc.) in YAML of the advanced tunnel.
+	Config    string
 	CreatedAt time.Time
 	Stats     config.ServiceStats
 }
@@ -64,6 +91,42 @@ func EndpointOption(endpoint string) Option {
 	}
 }
 
+func StrategyOption(strategy string) Option {
+	return func(opts *Options) {
+		opts.Strategy = strategy
+	}
+}
+
+func RoutesOption(routes []config.HTTPRoute) Option {
+	return func(opts *Options) {
+		opts.Routes = routes
+	}
+}
+
+func RequestHeadersOption(headers []config.HTTPHeader) Option {
+	return func(opts *Options) {
+		opts.RequestHeaders = headers
+	}
+}
+
+func ResponseHeadersOption(headers []config.HTTPHeader) Option {
+	return func(opts *Options) {
+		opts.ResponseHeaders = headers
+	}
+}
+
+func TransportOption(transport *config.Transport) Option {
+	return func(opts *Options) {
+		opts.Transport = transport
+	}
+}
+
+func ProxiesOption(proxies []config.Proxy) Option {
+	return func(opts *Options) {
+		opts.Proxies = proxies
+	}
+}
+
 func HostnameOption(hostname string) Option {
 	return func(opts *Options) {
 		opts.Hostname = hostname
@@ -88,6 +151,12 @@ func EnableTLSOption(b bool) Option {
 	}
 }
 
+func TLSOption(tls *config.TLS) Option {
+	return func(opts *Options) {
+		opts.TLS = tls
+	}
+}
+
 func KeepaliveOption(b bool) Option {
 	return func(opts *Options) {
 		opts.Keepalive = b
@@ -100,6 +169,12 @@ func TTLOption(ttl int) Option {
 	}
 }
 
+func ConfigOption(config string) Option {
+	return func(opts *Options) {
+		opts.Config = config
+	}
+}
+
 func CreatedAtOption(createdAt time.Time) Option {
 	return func(opts *Options) {
 		opts.CreatedAt = createdAt
@@ -110,6 +185,17 @@ type ServiceStatus interface {
 	Status() *xservice.Status
 }
 
+// MultiService is implemented by the tunnels running more than one service,
+// the stats of the tunnel are the sum of the stats of all the services instead of the stats in Status.
+type MultiService interface {
+	ServiceStats() stats.Stats
+}
+
+// Replayer is implemented by the tunnels which are able to re-send captured requests.
+type Replayer interface {
+	Replay(ctx context.Context, req *http.Request) (*http.Response, error)
+}
+
 type Tunnel interface {
 	ID() string
 	Type() string
@@ -198,36 +284,63 @@ func Delete(id string) {
 		if s != nil && s.ID() == id {
 			s.Close()
 			tunnels.list[i] = nil
+			inspector.Delete(id)
+			history.Tunnels.Delete(id)
 			return
 		}
 	}
 }
 
-func ChainConfig(id string, name string) *xconfig.ChainConfig {
+// EndpointAddr returns the domain of the public entrypoint, see config.Settings.Entrypoint.
+func EndpointAddr() string {
+	if settings := config.Get().Settings; settings != nil && settings.Entrypoint != "" {
+		return settings.Entrypoint
+	}
+	return DefaultEndpointAddr
+}
+
+// ServerAddr returns the address of the tunnel server in host:port form, see config.Settings.Server.
+func ServerAddr() string {
+	server := DefaultServerName
+	if settings := config.Get().Settings; settings != nil && settings.Server != "" {
+		server = settings.Server
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, DefaultServerPort)
+	}
+	return server
+}
+
+// ServerName returns the hostname of the tunnel server used for TLS verification.
+func ServerName() string {
+	host, _, _ := net.SplitHostPort(ServerAddr())
+	return host
+}
+
+// ChainConfig returns the chain to the tunnel server for the tunnel id, see DialerConfig for the transport.
+// The hops of the upstream proxies are ahead of the hop of the tunnel server, see ProxiesOf.
+func ChainConfig(id string, name string, transport *config.Transport, proxies []config.Proxy) *xconfig.ChainConfig {
+	if err := ValidateUpstream(transport, proxies); err != nil {
+		logger.Default().Warnf("%s: %v", name, err)
+	}
 	return &xconfig.ChainConfig{
 		Name: name,
-		Hops: []*xconfig.HopConfig{
-			{
+		Hops: append(proxyHops(name, proxies),
+			&xconfig.HopConfig{
 				Name: name,
 				Nodes: []*xconfig.NodeConfig{
 					{
 						Name: name,
-						Addr: ServerAddr,
+						Addr: ServerAddr(),
 						Connector: &xconfig.ConnectorConfig{
 							Type:     "tunnel",
 							Metadata: map[string]any{"tunnel.id": id},
 						},
-						Dialer: &xconfig.DialerConfig{
-							Type: "wss",
-							TLS: &xconfig.TLSConfig{
-								Secure:     true,
-								ServerName: ServerName,
-							},
-						},
+						Dialer: DialerConfig(transport),
 					},
 				},
 			},
-		},
+		),
 	}
 }
 
@@ -237,17 +350,9 @@ func LoadConfig() {
 			continue
 		}
 
-		tun := createTunnel(cfg.Type, Options{
-			ID:        cfg.ID,
-			Name:      cfg.Name,
-			Endpoint:  cfg.Endpoint,
-			Hostname:  cfg.Hostname,
-			Username:  cfg.Username,
-			Password:  cfg.Password,
-			EnableTLS: cfg.EnableTLS,
-			CreatedAt: cfg.CreatedAt,
-			Stats:     cfg.Stats,
-		})
+		opts := OptionsOf(cfg)
+		opts.Stats = config.TunnelStats(cfg.ID)
+		tun := NewTunnel(cfg.Type, opts)
 		if tun == nil {
 			continue
 		}
@@ -273,22 +378,10 @@ func SaveConfig() error {
 			continue
 		}
 
-		opts := tun.Options()
-
-		cfg.Tunnels = append(cfg.Tunnels, &config.Tunnel{
-			ID:        tun.ID(),
-			Name:      tun.Name(),
-			Type:      tun.Type(),
-			Endpoint:  tun.Endpoint(),
-			Hostname:  opts.Hostname,
-			Username:  opts.Username,
-			Password:  opts.Password,
-			EnableTLS: opts.EnableTLS,
-			Favorite:  tun.IsFavorite(),
-			Closed:    tun.IsClosed(),
-			CreatedAt: opts.CreatedAt,
-			Stats:     tun.Stats(),
-		})
+		c := ConfigOf(tun)
+		c.Favorite = tun.IsFavorite()
+		c.Closed = tun.IsClosed()
+		cfg.Tunnels = append(cfg.Tunnels, c)
 	}
 
 	config.Set(cfg)
@@ -300,15 +393,153 @@ func SaveConfig() error {
 	return nil
 }
 
-func createTunnel(st string, opts Options) (t Tunnel) {
+// OptionsOf returns the options of the tunnel or entrypoint in the config, the stats are not set.
+func OptionsOf(cfg *config.Tunnel) Options {
+	return Options{
+		ID:              cfg.ID,
+		Name:            cfg.Name,
+		Endpoint:        cfg.Endpoint,
+		Strategy:        cfg.Strategy,
+		Routes:          cfg.Routes,
+		RequestHeaders:  cfg.RequestHeaders,
+		ResponseHeaders: cfg.ResponseHeaders,
+		Transport:       cfg.Transport,
+		Proxies:         cfg.Proxies,
+		Hostname:        cfg.Hostname,
+		Username:        cfg.Username,
+		Password:        cfg.Password,
+		EnableTLS:       cfg.EnableTLS,
+		TLS:             cfg.TLS,
+		Keepalive:       cfg.Keepalive,
+		TTL:             cfg.TTL,
+		Config:          cfg.Config,
+		CreatedAt:       cfg.CreatedAt,
+	}
+}
+
+// ConfigOf returns the config of the tunnel, the inverse of OptionsOf.
+// The favorite and closed state are left to the caller.
+func ConfigOf(tun Tunnel) *config.Tunnel {
+	opts := tun.Options()
+	return &config.Tunnel{
+		ID:              tun.ID(),
+		Name:            tun.Name(),
+		Type:            tun.Type(),
+		Endpoint:        tun.Endpoint(),
+		Strategy:        opts.Strategy,
+		Routes:          opts.Routes,
+		RequestHeaders:  opts.RequestHeaders,
+		ResponseHeaders: opts.ResponseHeaders,
+		Transport:       opts.Transport,
+		Proxies:         opts.Proxies,
+		Hostname:        opts.Hostname,
+		Username:        opts.Username,
+		Password:        opts.Password,
+		EnableTLS:       opts.EnableTLS,
+		TLS:             opts.TLS,
+		Keepalive:       opts.Keepalive,
+		TTL:             opts.TTL,
+		Config:          opts.Config,
+		CreatedAt:       opts.CreatedAt,
+	}
+}
+
+// ValidateOptions checks the options of a tunnel of type st which would otherwise only fail on running the tunnel.
+func ValidateOptions(st string, opts Options) error {
+	if err := ValidateTransport(opts.Transport); err != nil {
+		return err
+	}
+	if err := ValidateUpstream(opts.Transport, opts.Proxies); err != nil {
+		return err
+	}
+	if st == AdvancedTunnel {
+		return ValidateAdvancedConfig(opts.Config)
+	}
+	if st == ProxyTunnel && (opts.Username == "" || opts.Password == "") {
+		return ErrProxyAuth
+	}
+	if err := ValidateStrategy(opts.Strategy); err != nil {
+		return err
+	}
+	if err := ValidateRoutes(opts.Routes); err != nil {
+		return err
+	}
+	if err := ValidateHeaders(opts.RequestHeaders); err != nil {
+		return err
+	}
+	if err := ValidateHeaders(opts.ResponseHeaders); err != nil {
+		return err
+	}
+	if st == HTTPTunnel && opts.EnableTLS {
+		return ValidateTLS(opts.TLS)
+	}
+	return nil
+}
+
+// ValidateLoopback checks that the listen address addr only accepts the local connections,
+// the host must be localhost or a loopback IP, an empty or unspecified host listens on all the interfaces.
+func ValidateLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(host, "localhost") {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrLoopbackAddr, addr)
+}
+
+// SaveStats persists the stats separately from the config, see config.StatsStore.
+func SaveStats() error {
+	stats := make(map[string]config.ServiceStats)
+	for i := 0; i < Count(); i++ {
+		if tun := GetIndex(i); tun != nil {
+			stats[tun.ID()] = tun.Stats()
+		}
+	}
+	config.SetTunnelStats(stats)
+
+	if err := config.SaveStats(); err != nil {
+		logger.Default().Error(err)
+		return err
+	}
+	return nil
+}
+
+// Reload restarts all the running tunnels, so that the changed settings take effect.
+func Reload() {
+	for i := 0; i < Count(); i++ {
+		tun := GetIndex(i)
+		if tun == nil || tun.IsClosed() {
+			continue
+		}
+		if _, err := restart(tun); err != nil {
+			logger.Default().Error(err)
+		}
+	}
+}
+
+// NewTunnel creates a tunnel of the given type, nil is returned for an unknown type.
+func NewTunnel(st string, opts Options) (t Tunnel) {
 	options := []Option{
 		IDOption(opts.ID),
 		NameOption(opts.Name),
 		EndpointOption(opts.Endpoint),
+		StrategyOption(opts.Strategy),
+		RoutesOption(opts.Routes),
+		RequestHeadersOption(opts.RequestHeaders),
+		ResponseHeadersOption(opts.ResponseHeaders),
+		TransportOption(opts.Transport),
+		ProxiesOption(opts.Proxies),
 		HostnameOption(opts.Hostname),
 		UsernameOption(opts.Username),
 		PasswordOption(opts.Password),
 		EnableTLSOption(opts.EnableTLS),
+		TLSOption(opts.TLS),
+		ConfigOption(opts.Config),
 		CreatedAtOption(opts.CreatedAt),
 	}
 
@@ -321,6 +552,10 @@ func createTunnel(st string, opts Options) (t Tunnel) {
 		t = NewTCPTunnel(options...)
 	case UDPTunnel:
 		t = NewUDPTunnel(options...)
+	case ProxyTunnel:
+		t = NewProxyTunnel(options...)
+	case AdvancedTunnel:
+		t = NewAdvancedTunnel(options...)
 	default:
 		return nil
 	}
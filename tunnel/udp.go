@@ -90,7 +90,7 @@ func (s *udpTunnel) Endpoint() string {
 }
 
 func (s *udpTunnel) Entrypoint() string {
-	return fmt.Sprintf("%s.%s", s.endpoint, EndpointAddr)
+	return fmt.Sprintf("%s.%s", s.endpoint, EndpointAddr())
 }
 
 func (s *udpTunnel) Options() Options {
@@ -128,7 +128,7 @@ func (s *udpTunnel) init() error {
 
 	s.config = &config.Config{
 		Services: []*config.ServiceConfig{rudp},
-		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name)},
+		Chains:   []*config.ChainConfig{ChainConfig(s.opts.ID, s.opts.Name, s.opts.Transport, s.opts.Proxies)},
 	}
 	return nil
 }
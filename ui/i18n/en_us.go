@@ -20,14 +20,20 @@ var en_US = map[Key]string{
 	HTTPTunnelDesc:     "Expose local HTTP service to public network",
 	TCPTunnelDesc:      "Expose local TCP service to public network",
 	UDPTunnelDesc:      "Expose local UDP service to public network",
+	ProxyTunnelDesc:    "Expose a SOCKS5/HTTP proxy to access the local network",
+	AdvancedTunnelDesc: "Run services from a raw GOST config",
 	TCPEntrypointDesc:  "Create an entrypoint to the specified TCP tunnel",
 	UDPEntrypointDesc:  "Create an entrypoint to the specified UDP tunnel",
+	HTTPEntrypointDesc: "Create an HTTP reverse proxy to the specified HTTP tunnel",
+	FileEntrypointDesc: "Browse and download the files of the specified file tunnel",
 	OK:                 "OK",
 	Cancel:             "Cancel",
 	DeleteTunnel:       "Delete tunnel?",
 	DeleteEntrypoint:   "Delete entrypoint?",
 	ErrInvalidTunnelID: "invalid tunnel ID, should be a valid UUID",
 	ErrInvalidAddr:     "invalid address format, should be [IP]:PORT or [HOST]:PORT",
+	ErrLoopbackAddr:    "must be a loopback address, such as localhost:PORT or 127.0.0.1:PORT",
+	ErrAuthRequired:    "username and password are required, otherwise anyone could use the proxy",
 	ErrDigitOnly:       "Must contain only digits",
 	ErrDirectory:       "is not a directory",
 
@@ -40,5 +46,127 @@ var en_US = map[Key]string{
 	ThemeDark:   "Dark",
 	ThemeSystem: "System",
 
-	Inspector: "Traffic Inspector",
+	Server:           "Tunnel server",
+	PublicEntrypoint: "Public entrypoint domain",
+	SettingsApplied:  "Settings saved, running tunnels restarted",
+	ControlAPI:       "Control API",
+	Token:            "Token",
+	MetricsExporter:  "Prometheus metrics",
+	ConfigRecovered:  "The config file was corrupted and has been recovered from %s",
+	ConfigLost:       "The config file was corrupted and has been moved to %s",
+	ConfigReadOnly:   "The passwords in the config can not be decrypted (%s), changes will not be saved. Check the master passphrase or the OS keyring and restart",
+	NoSecretKey:      "The passwords can not be encrypted (%s), the config with them will not be saved. Set the master passphrase or check the OS keyring and restart",
+
+	Reconnecting:   "Reconnecting in %s (attempt %d)",
+	Reconnected:    "Reconnected after %d attempt(s)",
+	HealthUp:       "UP",
+	HealthDegraded: "DEGRADED",
+	HealthDown:     "DOWN",
+
+	Traffic:  "Traffic",
+	Inbound:  "Inbound",
+	Outbound: "Outbound",
+	Now:      "Now",
+	NoData:   "No data",
+
+	Inspector:    "Traffic Inspector",
+	Request:      "Request",
+	Response:     "Response",
+	Headers:      "Headers",
+	Body:         "Body",
+	Status:       "Status",
+	Duration:     "Duration",
+	Time:         "Time",
+	Client:       "Client",
+	Error:        "Error",
+	Replay:       "Replay",
+	ReplayDone:   "Request replayed",
+	ErrReplay:    "Replay failed",
+	NoRecords:    "No requests captured yet",
+	BinaryBody:   "binary data",
+	EmptyBody:    "empty",
+	ClearRecords: "Clear all captured requests?",
+
+	TruncatedBody: "incomplete, cut off at 64 KB, edit it to replay",
+	BackendTLSErr: "The endpoint is unreachable, the tunnel keeps running: %s",
+
+	Share:        "Share tunnels",
+	Export:       "Export",
+	Import:       "Import",
+	Format:       "Format",
+	StripSecrets: "Strip passwords",
+	KeepIDs:      "Replace tunnels with the same ID",
+	ExportSelect: "Select the tunnels and entrypoints to export",
+	ImportHint:   "Paste a YAML/JSON bundle or a gost-plus:// link",
+	Imported:     "%d created, %d replaced, %d skipped",
+	ReviewConfig: "The imported tunnels and entrypoints are stopped, review what they expose before starting them",
+	Copied:       "Copied to clipboard",
+
+	GostConfig:     "GOST Config (YAML)",
+	GostConfigHint: "services, chains, ... use chain \"tunnel\" to reach the server",
+
+	Protocol: "Protocol",
+
+	TunnelCredentials: "Tunnel credentials",
+	BrowseFiles:       "Open the address in a browser to browse and download the files",
+
+	Strategy:     "Load balancing",
+	RoundRobin:   "Round robin",
+	Random:       "Random",
+	Failover:     "Fail-over",
+	Backends:     "Backends",
+	Connections:  "Conns",
+	Errors:       "Errors",
+	EndpointHint: "Address, separate multiple backends with commas",
+
+	Routes:         "Routes",
+	RoutesDesc:     "Requests matching a route are forwarded to its endpoint, the first matching route wins, others go to the default endpoint",
+	PathPrefix:     "Path prefix, e.g. /api",
+	StripPrefix:    "Strip path prefix",
+	HeaderHint:     "Header, e.g. X-Env: staging",
+	ErrInvalidPath: "path must start with /",
+
+	RequestHeaders:  "Request headers",
+	ResponseHeaders: "Response headers",
+	HeaderName:      "Name",
+	HeaderValue:     "Value",
+	RemoveHeader:    "Remove",
+
+	Transport:        "Transport",
+	TransportPath:    "Path, e.g. /ws",
+	TransportOptions: "Options, e.g. keepalive=true,ttl=30s",
+
+	UpstreamProxies: "Upstream proxies",
+	ProxyURL:        "URL, e.g. http://user@proxy:3128",
+	SystemProxy:     "Use system proxy (HTTPS_PROXY)",
+	ErrInvalidProxy: "invalid proxy URL",
+
+	TLSCA:         "CA certificate (PEM), default is the system CAs",
+	TLSServerName: "Server name, default is the endpoint host",
+	TLSCert:       "Client certificate (PEM)",
+	TLSKey:        "Client key (PEM)",
+	TLSInsecure:   "Skip certificate verification",
+
+	ServerCertificate: "Server certificate",
+	ServerPins:        "Pinned SHA-256 fingerprints, one per line",
+	ErrInvalidPin:     "invalid SHA-256 fingerprint",
+	UntrustedCert:     "Untrusted server certificate",
+	UntrustedCertDesc: "The certificate of the tunnel server %s is not trusted.\n\nSubject: %s\nSHA-256: %s\n\nTrust this certificate and replace the pinned fingerprints with it?",
+	ChangedCertDesc:   "The certificate of the tunnel server %s is not one of the pinned certificates, it may have been renewed or the connection may be intercepted.\n\nSubject: %s\nSHA-256: %s\n\nTrust this certificate and pin its fingerprint?",
+
+	SelfHostedServer:   "Self-hosted server",
+	EnableServer:       "Run as tunnel server",
+	ServerFingerprint:  "Certificate SHA-256, pin it on the clients",
+	ListenAddr:         "Listen address",
+	EntrypointAddr:     "Public entrypoint address",
+	ServerCert:         "Certificate (PEM), default is self-signed",
+	ServerKey:          "Key (PEM)",
+	ClientCA:           "Client CA (PEM), requires client certificates",
+	IngressRules:       "Ingress rules",
+	IngressHint:        "hostname tunnel-ID, one per line",
+	ErrInvalidIngress:  "each rule must be a hostname and a tunnel ID",
+	ConnectedTunnels:   "Connected tunnels",
+	NoConnectedTunnels: "No tunnels connected",
+	Connectors:         "Connectors",
+	ConnectedFor:       "Up",
 }
@@ -70,14 +70,20 @@ const (
 	HTTPTunnelDesc     Key = "httpTunnelDesc"
 	TCPTunnelDesc      Key = "tcpTunnelDesc"
 	UDPTunnelDesc      Key = "udpTunnelDesc"
+	ProxyTunnelDesc    Key = "proxyTunnelDesc"
+	AdvancedTunnelDesc Key = "advancedTunnelDesc"
 	TCPEntrypointDesc  Key = "tcpEntrypointDesc"
 	UDPEntrypointDesc  Key = "udpEntrypointDesc"
+	HTTPEntrypointDesc Key = "httpEntrypointDesc"
+	FileEntrypointDesc Key = "fileEntrypointDesc"
 	OK                 Key = "ok"
 	Cancel             Key = "cancel"
 	DeleteTunnel       Key = "deleteTunnel"
 	DeleteEntrypoint   Key = "deleteEntrypoint"
 	ErrInvalidTunnelID Key = "errInvalidTunnelID"
 	ErrInvalidAddr     Key = "errInvalidAddr"
+	ErrLoopbackAddr    Key = "errLoopbackAddr"
+	ErrAuthRequired    Key = "errAuthRequired"
 	ErrDigitOnly       Key = "errDigitOnly"
 	ErrDirectory       Key = "errDir"
 
@@ -90,7 +96,129 @@ const (
 	ThemeDark   Key = "themeDark"
 	ThemeSystem Key = "themeSystem"
 
-	Inspector Key = "inspector"
+	Server           Key = "server"
+	PublicEntrypoint Key = "publicEntrypoint"
+	SettingsApplied  Key = "settingsApplied"
+	ControlAPI       Key = "controlAPI"
+	Token            Key = "token"
+	MetricsExporter  Key = "metricsExporter"
+	ConfigRecovered  Key = "configRecovered"
+	ConfigLost       Key = "configLost"
+	ConfigReadOnly   Key = "configReadOnly"
+	NoSecretKey      Key = "noSecretKey"
+
+	Reconnecting   Key = "reconnecting"
+	Reconnected    Key = "reconnected"
+	HealthUp       Key = "healthUp"
+	HealthDegraded Key = "healthDegraded"
+	HealthDown     Key = "healthDown"
+
+	Traffic  Key = "traffic"
+	Inbound  Key = "inbound"
+	Outbound Key = "outbound"
+	Now      Key = "now"
+	NoData   Key = "noData"
+
+	Inspector    Key = "inspector"
+	Request      Key = "request"
+	Response     Key = "response"
+	Headers      Key = "headers"
+	Body         Key = "body"
+	Status       Key = "status"
+	Duration     Key = "duration"
+	Time         Key = "time"
+	Client       Key = "client"
+	Error        Key = "error"
+	Replay       Key = "replay"
+	ReplayDone   Key = "replayDone"
+	ErrReplay    Key = "errReplay"
+	NoRecords    Key = "noRecords"
+	BinaryBody   Key = "binaryBody"
+	EmptyBody    Key = "emptyBody"
+	ClearRecords Key = "clearRecords"
+
+	TruncatedBody Key = "truncatedBody"
+	BackendTLSErr Key = "backendTLSErr"
+
+	Share        Key = "share"
+	Export       Key = "export"
+	Import       Key = "import"
+	Format       Key = "format"
+	StripSecrets Key = "stripSecrets"
+	KeepIDs      Key = "keepIDs"
+	ExportSelect Key = "exportSelect"
+	ImportHint   Key = "importHint"
+	Imported     Key = "imported"
+	ReviewConfig Key = "reviewConfig"
+	Copied       Key = "copied"
+
+	GostConfig     Key = "gostConfig"
+	GostConfigHint Key = "gostConfigHint"
+
+	Protocol Key = "protocol"
+
+	TunnelCredentials Key = "tunnelCredentials"
+	BrowseFiles       Key = "browseFiles"
+
+	Strategy     Key = "strategy"
+	RoundRobin   Key = "roundRobin"
+	Random       Key = "random"
+	Failover     Key = "failover"
+	Backends     Key = "backends"
+	Connections  Key = "connections"
+	Errors       Key = "errors"
+	EndpointHint Key = "endpointHint"
+
+	Routes         Key = "routes"
+	RoutesDesc     Key = "routesDesc"
+	PathPrefix     Key = "pathPrefix"
+	StripPrefix    Key = "stripPrefix"
+	HeaderHint     Key = "headerHint"
+	ErrInvalidPath Key = "errInvalidPath"
+
+	RequestHeaders  Key = "requestHeaders"
+	ResponseHeaders Key = "responseHeaders"
+	HeaderName      Key = "headerName"
+	HeaderValue     Key = "headerValue"
+	RemoveHeader    Key = "removeHeader"
+
+	Transport        Key = "transport"
+	TransportPath    Key = "transportPath"
+	TransportOptions Key = "transportOptions"
+
+	UpstreamProxies Key = "upstreamProxies"
+	ProxyURL        Key = "proxyURL"
+	SystemProxy     Key = "systemProxy"
+	ErrInvalidProxy Key = "errInvalidProxy"
+
+	TLSCA         Key = "tlsCA"
+	TLSServerName Key = "tlsServerName"
+	TLSCert       Key = "tlsCert"
+	TLSKey        Key = "tlsKey"
+	TLSInsecure   Key = "tlsInsecure"
+
+	ServerCertificate Key = "serverCertificate"
+	ServerPins        Key = "serverPins"
+	ErrInvalidPin     Key = "errInvalidPin"
+	UntrustedCert     Key = "untrustedCert"
+	UntrustedCertDesc Key = "untrustedCertDesc"
+	ChangedCertDesc   Key = "changedCertDesc"
+
+	SelfHostedServer   Key = "selfHostedServer"
+	EnableServer       Key = "enableServer"
+	ServerFingerprint  Key = "serverFingerprint"
+	ListenAddr         Key = "listenAddr"
+	EntrypointAddr     Key = "entrypointAddr"
+	ServerCert         Key = "serverCert"
+	ServerKey          Key = "serverKey"
+	ClientCA           Key = "clientCA"
+	IngressRules       Key = "ingressRules"
+	IngressHint        Key = "ingressHint"
+	ErrInvalidIngress  Key = "errInvalidIngress"
+	ConnectedTunnels   Key = "connectedTunnels"
+	NoConnectedTunnels Key = "noConnectedTunnels"
+	Connectors         Key = "connectors"
+	ConnectedFor       Key = "connectedFor"
 )
 
 type Key string
@@ -20,14 +20,20 @@ var zh_CN = map[Key]string{
 	HTTPTunnelDesc:     "将本地的一个HTTP服务暴露到公网",
 	TCPTunnelDesc:      "将本地的一个TCP服务暴露到公网",
 	UDPTunnelDesc:      "将本地的一个UDP服务暴露到公网",
+	ProxyTunnelDesc:    "暴露一个SOCKS5/HTTP代理以访问本地网络",
+	AdvancedTunnelDesc: "使用原始的GOST配置运行服务",
 	TCPEntrypointDesc:  "创建一个指定TCP隧道的入口点",
 	UDPEntrypointDesc:  "创建一个指定UDP隧道的入口点",
+	HTTPEntrypointDesc: "创建一个指定HTTP隧道的反向代理",
+	FileEntrypointDesc: "浏览和下载指定文件隧道中的文件",
 	OK:                 "确认",
 	Cancel:             "取消",
 	DeleteTunnel:       "删除隧道？",
 	DeleteEntrypoint:   "删除入口点？",
 	ErrInvalidTunnelID: "无效的隧道ID， 仅支持合法的UUID格式，例如：6bcb409c-dd0f-4ce7-9869-651c52c09d1c",
 	ErrInvalidAddr:     "无效的地址格式，仅支持[IP]:PORT或[HOST]:PORT",
+	ErrLoopbackAddr:    "必须为本地回环地址，例如localhost:PORT或127.0.0.1:PORT",
+	ErrAuthRequired:    "必须设置用户名和密码，否则任何人都可以使用此代理",
 	ErrDigitOnly:       "仅能输入数字",
 	ErrDirectory:       "不是一个目录",
 
@@ -40,5 +46,127 @@ var zh_CN = map[Key]string{
 	ThemeDark:   "深色",
 	ThemeSystem: "系统",
 
-	Inspector: "流量观察",
+	Server:           "隧道服务器",
+	PublicEntrypoint: "公网入口域名",
+	SettingsApplied:  "设置已保存，运行中的隧道已重启",
+	ControlAPI:       "控制 API",
+	Token:            "令牌",
+	MetricsExporter:  "Prometheus 指标",
+	ConfigRecovered:  "配置文件已损坏，已从备份 %s 恢复",
+	ConfigLost:       "配置文件已损坏，已移动到 %s",
+	ConfigReadOnly:   "无法解密配置中的密码（%s），修改将不会保存。请检查主密码或系统钥匙串后重启",
+	NoSecretKey:      "无法加密密码（%s），包含密码的配置将不会保存。请设置主密码或检查系统钥匙串后重启",
+
+	Reconnecting:   "%s 后重连 (第 %d 次)",
+	Reconnected:    "已重连 (共 %d 次)",
+	HealthUp:       "正常",
+	HealthDegraded: "降级",
+	HealthDown:     "中断",
+
+	Traffic:  "流量",
+	Inbound:  "入站",
+	Outbound: "出站",
+	Now:      "现在",
+	NoData:   "暂无数据",
+
+	Inspector:    "流量观察",
+	Request:      "请求",
+	Response:     "响应",
+	Headers:      "头部",
+	Body:         "主体",
+	Status:       "状态",
+	Duration:     "耗时",
+	Time:         "时间",
+	Client:       "客户端",
+	Error:        "错误",
+	Replay:       "重放",
+	ReplayDone:   "请求已重放",
+	ErrReplay:    "重放失败",
+	NoRecords:    "暂无请求记录",
+	BinaryBody:   "二进制数据",
+	EmptyBody:    "空",
+	ClearRecords: "清空所有请求记录？",
+
+	TruncatedBody: "不完整，已截断为 64 KB，编辑后才能重放",
+	BackendTLSErr: "无法连接到后端服务，隧道仍在运行：%s",
+
+	Share:        "分享隧道",
+	Export:       "导出",
+	Import:       "导入",
+	Format:       "格式",
+	StripSecrets: "移除密码",
+	KeepIDs:      "替换相同 ID 的隧道",
+	ExportSelect: "选择要导出的隧道和入口点",
+	ImportHint:   "粘贴 YAML/JSON 配置包或 gost-plus:// 链接",
+	Imported:     "新建 %d 个, 替换 %d 个, 跳过 %d 个",
+	ReviewConfig: "导入的隧道和入口均未启动，请检查其暴露的内容后再启动",
+	Copied:       "已复制到剪贴板",
+
+	GostConfig:     "GOST配置 (YAML)",
+	GostConfigHint: "services, chains, ... 使用名为 \"tunnel\" 的转发链连接到服务器",
+
+	Protocol: "协议",
+
+	TunnelCredentials: "隧道认证信息",
+	BrowseFiles:       "在浏览器中打开此地址以浏览和下载文件",
+
+	Strategy:     "负载均衡",
+	RoundRobin:   "轮询",
+	Random:       "随机",
+	Failover:     "故障转移",
+	Backends:     "后端服务",
+	Connections:  "连接",
+	Errors:       "错误",
+	EndpointHint: "地址，多个后端服务以逗号分隔",
+
+	Routes:         "路由",
+	RoutesDesc:     "匹配路由的请求转发到路由的服务地址，按顺序优先匹配，其余请求转发到默认服务地址",
+	PathPrefix:     "路径前缀，例如 /api",
+	StripPrefix:    "去除路径前缀",
+	HeaderHint:     "请求头，例如 X-Env: staging",
+	ErrInvalidPath: "路径必须以 / 开头",
+
+	RequestHeaders:  "请求头",
+	ResponseHeaders: "响应头",
+	HeaderName:      "名称",
+	HeaderValue:     "值",
+	RemoveHeader:    "删除",
+
+	Transport:        "传输方式",
+	TransportPath:    "路径，例如 /ws",
+	TransportOptions: "选项，例如 keepalive=true,ttl=30s",
+
+	UpstreamProxies: "上游代理",
+	ProxyURL:        "地址，例如 http://user@proxy:3128",
+	SystemProxy:     "使用系统代理 (HTTPS_PROXY)",
+	ErrInvalidProxy: "无效的代理地址",
+
+	TLSCA:         "CA 证书 (PEM)，默认使用系统 CA",
+	TLSServerName: "服务器名称，默认为端点主机名",
+	TLSCert:       "客户端证书 (PEM)",
+	TLSKey:        "客户端私钥 (PEM)",
+	TLSInsecure:   "跳过证书验证",
+
+	ServerCertificate: "服务器证书",
+	ServerPins:        "固定的 SHA-256 指纹，每行一个",
+	ErrInvalidPin:     "无效的 SHA-256 指纹",
+	UntrustedCert:     "不受信任的服务器证书",
+	UntrustedCertDesc: "隧道服务器 %s 的证书不受信任。\n\n主题：%s\nSHA-256：%s\n\n是否信任此证书并固定其指纹？",
+	ChangedCertDesc:   "隧道服务器 %s 的证书不是已固定的证书，可能已被更新，也可能连接被拦截。\n\n主题：%s\nSHA-256：%s\n\n是否信任此证书并用其指纹替换已固定的指纹？",
+
+	SelfHostedServer:   "自建服务器",
+	EnableServer:       "作为隧道服务器运行",
+	ServerFingerprint:  "证书 SHA-256，在客户端固定此指纹",
+	ListenAddr:         "监听地址",
+	EntrypointAddr:     "公网入口地址",
+	ServerCert:         "证书 (PEM)，默认使用自签名证书",
+	ServerKey:          "私钥 (PEM)",
+	ClientCA:           "客户端 CA (PEM)，要求客户端证书",
+	IngressRules:       "入口规则",
+	IngressHint:        "主机名 隧道ID，每行一条",
+	ErrInvalidIngress:  "每条规则必须包含主机名和隧道 ID",
+	ConnectedTunnels:   "已连接的隧道",
+	NoConnectedTunnels: "没有已连接的隧道",
+	Connectors:         "连接器",
+	ConnectedFor:       "运行",
 }
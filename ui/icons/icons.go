@@ -62,6 +62,10 @@ var (
 	IconInfo                 = mustIcon(icons.ActionInfo)
 	IconAlert                = mustIcon(icons.AlertErrorOutline)
 	IconExplore              = mustIcon(icons.ActionExplore)
+	IconReplay               = mustIcon(icons.AVReplay)
+	IconShare                = mustIcon(icons.SocialShare)
+	IconFolderOpen           = mustIcon(icons.FileFolderOpen)
+	IconServer               = mustIcon(icons.ActionDNS)
 )
 
 func mustIcon(data []byte) *widget.Icon {
@@ -52,6 +52,16 @@ func NewPage(r *page.Router) page.Page {
 				desc: i18n.UDPEntrypointDesc,
 				path: page.PageEntrypointUDP,
 			},
+			{
+				name: "HTTP",
+				desc: i18n.HTTPEntrypointDesc,
+				path: page.PageEntrypointHTTP,
+			},
+			{
+				name: "File",
+				desc: i18n.FileEntrypointDesc,
+				path: page.PageEntrypointFile,
+			},
 		},
 	}
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/gost.plus/tunnel"
 	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/go-gost/gost.plus/tunnel/history"
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/icons"
 	"github.com/go-gost/gost.plus/ui/page"
@@ -45,6 +46,8 @@ type tcpPage struct {
 	edit bool
 
 	delDialog ui_widget.Dialog
+
+	chart *ui_widget.TrafficChart
 }
 
 func NewPage(r *page.Router) page.Page {
@@ -72,6 +75,7 @@ func NewPage(r *page.Router) page.Page {
 		delDialog: ui_widget.Dialog{
 			Title: i18n.DeleteEntrypoint,
 		},
+		chart: ui_widget.NewTrafficChart(),
 	}
 }
 
@@ -242,13 +246,19 @@ func (p *tcpPage) Layout(gtx C) D {
 			})
 		}),
 		layout.Flexed(1, func(gtx C) D {
-			return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+			return p.list.Layout(gtx, 2, func(gtx C, index int) D {
 				return layout.Inset{
 					Top:    8,
 					Bottom: 8,
 					Left:   8,
 					Right:  8,
 				}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if index == 1 {
+						if p.id == "" {
+							return D{}
+						}
+						return p.chart.Layout(gtx, th, history.EntryPoints.Lookup(p.id))
+					}
 					return p.layout(gtx, th)
 				})
 			})
@@ -359,7 +369,10 @@ func (p *tcpPage) create() error {
 func (p *tcpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 	defer entrypoint.SaveConfig()
 
+	// the options which are not in the form are kept.
+	var prev tunnel.Options
 	if t := entrypoint.Get(p.id); t != nil {
+		prev = t.Options()
 		t.Close()
 	}
 
@@ -368,6 +381,8 @@ func (p *tcpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 			tunnel.IDOption(strings.ToLower(strings.TrimSpace(p.tunnelID.Text()))),
 			tunnel.NameOption(strings.TrimSpace(p.name.Text())),
 			tunnel.EndpointOption(strings.TrimSpace(p.entrypoint.Text())),
+			tunnel.TransportOption(prev.Transport),
+			tunnel.ProxiesOption(prev.Proxies),
 		}
 	}
 	ep := entrypoint.NewTCPEntryPoint(opts...)
@@ -398,6 +413,8 @@ func (p *tcpPage) onoff() {
 			tunnel.IDOption(opts.ID),
 			tunnel.NameOption(opts.Name),
 			tunnel.EndpointOption(opts.Endpoint),
+			tunnel.TransportOption(opts.Transport),
+			tunnel.ProxiesOption(opts.Proxies),
 			tunnel.CreatedAtOption(opts.CreatedAt),
 		)
 	} else {
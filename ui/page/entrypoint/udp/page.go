@@ -15,6 +15,7 @@ import (
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/gost.plus/tunnel"
 	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/go-gost/gost.plus/tunnel/history"
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/icons"
 	"github.com/go-gost/gost.plus/ui/page"
@@ -50,6 +51,8 @@ type udpPage struct {
 	edit bool
 
 	delDialog ui_widget.Dialog
+
+	chart *ui_widget.TrafficChart
 }
 
 func NewPage(r *page.Router) page.Page {
@@ -85,6 +88,7 @@ func NewPage(r *page.Router) page.Page {
 		delDialog: ui_widget.Dialog{
 			Title: i18n.DeleteEntrypoint,
 		},
+		chart: ui_widget.NewTrafficChart(),
 	}
 }
 
@@ -260,13 +264,19 @@ func (p *udpPage) Layout(gtx C) D {
 			})
 		}),
 		layout.Flexed(1, func(gtx C) D {
-			return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+			return p.list.Layout(gtx, 2, func(gtx C, index int) D {
 				return layout.Inset{
 					Top:    8,
 					Bottom: 8,
 					Left:   8,
 					Right:  8,
 				}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if index == 1 {
+						if p.id == "" {
+							return D{}
+						}
+						return p.chart.Layout(gtx, th, history.EntryPoints.Lookup(p.id))
+					}
 					return p.layout(gtx, th)
 				})
 			})
@@ -416,7 +426,10 @@ func (p *udpPage) create() error {
 func (p *udpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 	defer entrypoint.SaveConfig()
 
+	// the options which are not in the form are kept.
+	var prev tunnel.Options
 	if t := entrypoint.Get(p.id); t != nil {
+		prev = t.Options()
 		t.Close()
 	}
 
@@ -429,6 +442,8 @@ func (p *udpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 			tunnel.EndpointOption(strings.TrimSpace(p.entrypoint.Text())),
 			tunnel.KeepaliveOption(p.keepalive.Value),
 			tunnel.TTLOption(ttl),
+			tunnel.TransportOption(prev.Transport),
+			tunnel.ProxiesOption(prev.Proxies),
 		}
 	}
 	ep := entrypoint.NewUDPEntryPoint(opts...)
@@ -461,6 +476,8 @@ func (p *udpPage) onoff() {
 			tunnel.EndpointOption(opts.Endpoint),
 			tunnel.KeepaliveOption(opts.Keepalive),
 			tunnel.TTLOption(opts.TTL),
+			tunnel.TransportOption(opts.Transport),
+			tunnel.ProxiesOption(opts.Proxies),
 			tunnel.CreatedAtOption(opts.CreatedAt),
 		)
 	} else {
@@ -0,0 +1,114 @@
+package page
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"gioui.org/x/explorer"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/ui/icons"
+	"github.com/go-gost/gost.plus/ui/theme"
+	ui_widget "github.com/go-gost/gost.plus/ui/widget"
+)
+
+// pemExtensions are the file extensions offered by the file chooser.
+var pemExtensions = []string{".pem", ".crt", ".cer", ".key"}
+
+// FileField is the path of a PEM file, which can be typed or chosen by the file chooser.
+type FileField struct {
+	component.TextField
+	btnChoose widget.Clickable
+	chosen    chan string
+}
+
+func NewFileField() *FileField {
+	return &FileField{
+		TextField: component.TextField{Editor: widget.Editor{SingleLine: true}},
+		chosen:    make(chan string, 1),
+	}
+}
+
+func (f *FileField) Layout(gtx C, th *material.Theme, router *Router, hint string) D {
+	if f.btnChoose.Clicked(gtx) {
+		go f.choose(router)
+	}
+	select {
+	case path := <-f.chosen:
+		f.SetText(path)
+	default:
+	}
+
+	return layout.Flex{
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return f.TextField.Layout(gtx, th, hint)
+		}),
+		layout.Rigid(func(gtx C) D {
+			btn := material.IconButton(th, &f.btnChoose, icons.IconFolderOpen, "Choose file")
+			btn.Color = th.Fg
+			btn.Background = theme.Current().ContentSurfaceBg
+			return btn.Layout(gtx)
+		}),
+	)
+}
+
+func (f *FileField) choose(router *Router) {
+	r, err := router.ChooseFile(pemExtensions...)
+	if err != nil {
+		if !errors.Is(err, explorer.ErrUserDecline) {
+			router.Notify(ui_widget.Message{
+				Type:    ui_widget.Error,
+				Content: err.Error(),
+			})
+		}
+		return
+	}
+	defer r.Close()
+
+	path, err := localFile(r)
+	if err != nil {
+		router.Notify(ui_widget.Message{
+			Type:    ui_widget.Error,
+			Content: err.Error(),
+		})
+		return
+	}
+
+	select {
+	case f.chosen <- path:
+	default:
+	}
+	router.Invalidate()
+}
+
+// localFile returns the path of the chosen file. On the platforms where the chooser only gives the content,
+// such as Android, the content is copied to the config directory.
+func localFile(r io.Reader) (string, error) {
+	if f, ok := r.(*os.File); ok {
+		return f.Name(), nil
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	dir := filepath.Join(config.Dir(), "certs")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, hex.EncodeToString(sum[:8])+".pem")
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
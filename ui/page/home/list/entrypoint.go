@@ -11,6 +11,7 @@ import (
 	"gioui.org/layout"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/go-gost/gost.plus/tunnel"
 	"github.com/go-gost/gost.plus/tunnel/entrypoint"
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/icons"
@@ -69,6 +70,10 @@ func (l *entrypointList) Layout(gtx C, th *material.Theme) D {
 				path = page.PageEntrypointTCP
 			case entrypoint.UDPEntryPoint:
 				path = page.PageEntrypointUDP
+			case entrypoint.HTTPEntryPoint:
+				path = page.PageEntrypointHTTP
+			case entrypoint.FileEntryPoint:
+				path = page.PageEntrypointFile
 			}
 			l.router.Goto(page.Route{
 				Path: path,
@@ -111,6 +116,9 @@ func (l *entrypointList) Layout(gtx C, th *material.Theme) D {
 									if t.Err() != nil {
 										c = colornames.Red600
 									}
+									if state, ok := entrypoint.Retry(t.ID()); ok && state.Retrying() {
+										c = colornames.Amber700
+									}
 									if t.IsClosed() {
 										c = colornames.Grey600
 									}
@@ -124,7 +132,7 @@ func (l *entrypointList) Layout(gtx C, th *material.Theme) D {
 								Alignment: layout.Middle,
 								Spacing:   layout.SpaceBetween,
 							}.Layout(gtx,
-								layout.Flexed(1, material.Body2(th, fmt.Sprintf("%s: %s", i18n.Type.Value(), strings.ToUpper(t.Type()))).Layout),
+								layout.Flexed(1, material.Body2(th, fmt.Sprintf("%s: %s (%s)", i18n.Type.Value(), strings.ToUpper(t.Type()), strings.ToUpper(tunnel.TransportOf(t.Options().Transport).Type))).Layout),
 								layout.Rigid(layout.Spacer{Width: 4}.Layout),
 								layout.Rigid(func(gtx C) D {
 									if createdAt := t.Options().CreatedAt; !createdAt.IsZero() {
@@ -137,6 +145,13 @@ func (l *entrypointList) Layout(gtx C, th *material.Theme) D {
 						}),
 						layout.Rigid(layout.Spacer{Height: 4}.Layout),
 						layout.Rigid(material.Body2(th, fmt.Sprintf("%s: %s", i18n.Endpoint.Value(), t.Endpoint())).Layout),
+						layout.Rigid(func(gtx C) D {
+							state, ok := entrypoint.Retry(t.ID())
+							if !ok || t.IsClosed() {
+								return D{}
+							}
+							return layoutRetry(gtx, th, state)
+						}),
 						layout.Rigid(layout.Spacer{Height: 4}.Layout),
 						layout.Rigid(func(gtx C) D {
 							return layout.Flex{
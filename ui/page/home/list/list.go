@@ -1,8 +1,16 @@
 package list
 
 import (
+	"fmt"
+	"image/color"
+	"time"
+
 	"gioui.org/layout"
 	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
 )
 
 type C = layout.Context
@@ -17,3 +25,70 @@ type List interface {
 	Layout(gtx C, th *material.Theme) D
 	Filter(f Filter)
 }
+
+// layoutRetry shows the reconnection state and the last error of a failed tunnel or entrypoint.
+func layoutRetry(gtx C, th *material.Theme, state tunnel.RetryState) D {
+	var text string
+	if state.Retrying() {
+		d := time.Until(state.Next).Round(time.Second)
+		if d < 0 {
+			d = 0
+		}
+		text = fmt.Sprintf(i18n.Reconnecting.Value(), d, state.Attempts+1)
+	} else {
+		text = fmt.Sprintf(i18n.Reconnected.Value(), state.Attempts)
+	}
+	if state.Err != nil {
+		text = fmt.Sprintf("%s: %v", text, state.Err)
+	}
+
+	return layout.Inset{Top: 4}.Layout(gtx, func(gtx C) D {
+		label := material.Body2(th, text)
+		label.MaxLines = 2
+		if state.Retrying() {
+			label.Color = color.NRGBA(colornames.Amber900)
+		}
+		return label.Layout(gtx)
+	})
+}
+
+// layoutHealth shows the latest health check result of the tunnel as a badge.
+func layoutHealth(gtx C, th *material.Theme, health tunnel.Health) D {
+	var c color.NRGBA
+	var text string
+	switch health.State {
+	case tunnel.HealthUp:
+		c = color.NRGBA(colornames.Green600)
+		text = i18n.HealthUp.Value()
+	case tunnel.HealthDegraded:
+		c = color.NRGBA(colornames.Orange700)
+		text = i18n.HealthDegraded.Value()
+	case tunnel.HealthDown:
+		c = color.NRGBA(colornames.Red600)
+		text = i18n.HealthDown.Value()
+	default:
+		return D{}
+	}
+	if health.ServerErr == nil {
+		text = fmt.Sprintf("%s %dms", text, health.Latency.Milliseconds())
+	}
+
+	return component.SurfaceStyle{
+		Theme: th,
+		ShadowStyle: component.ShadowStyle{
+			CornerRadius: 4,
+		},
+		Fill: c,
+	}.Layout(gtx, func(gtx C) D {
+		return layout.Inset{
+			Top:    2,
+			Bottom: 2,
+			Left:   6,
+			Right:  6,
+		}.Layout(gtx, func(gtx C) D {
+			label := material.Caption(th, text)
+			label.Color = color.NRGBA(colornames.White)
+			return label.Layout(gtx)
+		})
+	})
+}
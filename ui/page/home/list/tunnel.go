@@ -74,6 +74,10 @@ func (l *tunnelList) Layout(gtx C, th *material.Theme) D {
 				path = page.PageTunnelTCP
 			case tunnel.UDPTunnel:
 				path = page.PageTunnelUDP
+			case tunnel.ProxyTunnel:
+				path = page.PageTunnelProxy
+			case tunnel.AdvancedTunnel:
+				path = page.PageTunnelAdvanced
 			}
 			l.router.Goto(page.Route{
 				Path: path,
@@ -108,6 +112,15 @@ func (l *tunnelList) Layout(gtx C, th *material.Theme) D {
 									label.Font.Weight = font.SemiBold
 									return label.Layout(gtx)
 								}),
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									health, ok := tunnel.GetHealth(t.ID())
+									if !ok || t.IsClosed() {
+										return layout.Dimensions{}
+									}
+									return layout.Inset{Left: 4}.Layout(gtx, func(gtx C) D {
+										return layoutHealth(gtx, th, health)
+									})
+								}),
 								layout.Rigid(layout.Spacer{Width: 4}.Layout),
 								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 									gtx.Constraints.Min.X = gtx.Dp(12)
@@ -116,6 +129,9 @@ func (l *tunnelList) Layout(gtx C, th *material.Theme) D {
 									if t.Err() != nil {
 										c = colornames.Red600
 									}
+									if state, ok := tunnel.Retry(t.ID()); ok && state.Retrying() {
+										c = colornames.Amber700
+									}
 									if t.IsClosed() {
 										c = colornames.Grey600
 									}
@@ -129,7 +145,7 @@ func (l *tunnelList) Layout(gtx C, th *material.Theme) D {
 								Alignment: layout.Middle,
 								Spacing:   layout.SpaceBetween,
 							}.Layout(gtx,
-								layout.Flexed(1, material.Body2(th, fmt.Sprintf("%s: %s", i18n.Type.Value(), strings.ToUpper(t.Type()))).Layout),
+								layout.Flexed(1, material.Body2(th, fmt.Sprintf("%s: %s (%s)", i18n.Type.Value(), strings.ToUpper(t.Type()), strings.ToUpper(tunnel.TransportOf(t.Options().Transport).Type))).Layout),
 								layout.Rigid(layout.Spacer{Width: 4}.Layout),
 								layout.Rigid(func(gtx C) D {
 									if createdAt := t.Options().CreatedAt; !createdAt.IsZero() {
@@ -142,6 +158,13 @@ func (l *tunnelList) Layout(gtx C, th *material.Theme) D {
 						}),
 						layout.Rigid(layout.Spacer{Height: 4}.Layout),
 						layout.Rigid(material.Body2(th, fmt.Sprintf("%s: %s", i18n.Endpoint.Value(), t.Endpoint())).Layout),
+						layout.Rigid(func(gtx C) D {
+							state, ok := tunnel.Retry(t.ID())
+							if !ok || t.IsClosed() {
+								return D{}
+							}
+							return layoutRetry(gtx, th, state)
+						}),
 						layout.Rigid(layout.Spacer{Height: 4}.Layout),
 						layout.Rigid(func(gtx C) D {
 							return layout.Flex{
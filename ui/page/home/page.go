@@ -32,6 +32,8 @@ type homePage struct {
 	nav         *ui_widget.Nav
 	pages       []navPage
 	btnAdd      widget.Clickable
+	btnShare    widget.Clickable
+	btnServer   widget.Clickable
 	btnSettings widget.Clickable
 }
 
@@ -111,6 +113,32 @@ func (p *homePage) Layout(gtx C) D {
 								return btn.Layout(gtx)
 							}),
 							layout.Rigid(layout.Spacer{Width: 8}.Layout),
+							layout.Rigid(func(gtx C) D {
+								if p.btnShare.Clicked(gtx) {
+									p.router.Goto(page.Route{
+										Path: page.PageShare,
+									})
+								}
+
+								btn := material.IconButton(th, &p.btnShare, icons.IconShare, "Share")
+								btn.Color = th.Fg
+								btn.Background = th.Bg
+								return btn.Layout(gtx)
+							}),
+							layout.Rigid(layout.Spacer{Width: 8}.Layout),
+							layout.Rigid(func(gtx C) D {
+								if p.btnServer.Clicked(gtx) {
+									p.router.Goto(page.Route{
+										Path: page.PageServer,
+									})
+								}
+
+								btn := material.IconButton(th, &p.btnServer, icons.IconServer, "Server")
+								btn.Color = th.Fg
+								btn.Background = th.Bg
+								return btn.Layout(gtx)
+							}),
+							layout.Rigid(layout.Spacer{Width: 8}.Layout),
 							layout.Rigid(func(gtx C) D {
 								if p.btnSettings.Clicked(gtx) {
 									p.router.Goto(page.Route{
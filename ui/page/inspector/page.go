@@ -0,0 +1,582 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/inspector"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/icons"
+	"github.com/go-gost/gost.plus/ui/page"
+	"github.com/go-gost/gost.plus/ui/theme"
+	ui_widget "github.com/go-gost/gost.plus/ui/widget"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+)
+
+const (
+	maxBodyPreview = 4 * 1024
+)
+
+type C = layout.Context
+type D = layout.Dimensions
+
+type recordState struct {
+	btn widget.Clickable
+}
+
+type inspectorPage struct {
+	router *page.Router
+
+	btnBack   widget.Clickable
+	btnClear  widget.Clickable
+	btnEdit   widget.Clickable
+	btnReplay widget.Clickable
+
+	list   widget.List
+	states []recordState
+
+	id     string
+	record *inspector.Record
+
+	edit   bool
+	header component.TextField
+	body   component.TextField
+
+	clearDialog ui_widget.Dialog
+}
+
+func NewPage(r *page.Router) page.Page {
+	return &inspectorPage{
+		router: r,
+		list: widget.List{
+			List: layout.List{
+				Axis: layout.Vertical,
+			},
+		},
+		clearDialog: ui_widget.Dialog{
+			Title: i18n.ClearRecords,
+		},
+	}
+}
+
+func (p *inspectorPage) Init(opts ...page.PageOption) {
+	var options page.PageOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	p.id = options.ID
+	p.list.Position = layout.Position{}
+	p.selectRecord(nil)
+}
+
+func (p *inspectorPage) Destroy() {
+	p.selectRecord(nil)
+}
+
+func (p *inspectorPage) selectRecord(r *inspector.Record) {
+	p.record = r
+	p.edit = false
+	p.header.Clear()
+	p.body.Clear()
+
+	if r == nil {
+		return
+	}
+
+	var keys []string
+	for k := range r.Request.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range r.Request.Header[k] {
+			fmt.Fprintf(&b, "%s: %s\n", k, v)
+		}
+	}
+	p.header.SetText(b.String())
+	p.body.SetText(string(r.Request.Body))
+}
+
+func (p *inspectorPage) Layout(gtx C) D {
+	if p.btnBack.Clicked(gtx) {
+		if p.record != nil {
+			p.selectRecord(nil)
+		} else {
+			p.router.Back()
+		}
+	}
+
+	if p.btnEdit.Clicked(gtx) {
+		p.edit = !p.edit
+	}
+
+	if p.btnReplay.Clicked(gtx) && p.record != nil {
+		p.replay()
+	}
+
+	if p.btnClear.Clicked(gtx) {
+		p.clearDialog.Clicked = func(ok bool) {
+			if ok {
+				inspector.Get(p.id).Clear()
+				p.selectRecord(nil)
+			}
+			p.router.HideModal(gtx)
+		}
+		p.router.ShowModal(gtx, func(gtx page.C, th *material.Theme) page.D {
+			return p.clearDialog.Layout(gtx, th)
+		})
+	}
+
+	th := p.router.Theme
+
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		// header
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{
+				Top:    8,
+				Bottom: 8,
+				Left:   8,
+				Right:  8,
+			}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{
+					Spacing:   layout.SpaceBetween,
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						btn := material.IconButton(th, &p.btnBack, icons.IconBack, "Back")
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: 8}.Layout),
+					layout.Flexed(1, func(gtx C) D {
+						title := i18n.Inspector.Value()
+						if tun := tunnel.Get(p.id); tun != nil {
+							title = fmt.Sprintf("%s - %s", title, tun.Name())
+						}
+						return material.H6(th, title).Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: 8}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if p.record == nil {
+							return D{}
+						}
+						if p.edit {
+							btn := material.IconButton(th, &p.btnEdit, icons.IconDone, "Done")
+							btn.Color = th.Fg
+							btn.Background = th.Bg
+							return btn.Layout(gtx)
+						}
+						btn := material.IconButton(th, &p.btnEdit, icons.IconEdit, "Edit")
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: 8}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if p.record == nil {
+							return D{}
+						}
+						btn := material.IconButton(th, &p.btnReplay, icons.IconReplay, i18n.Replay.Value())
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx C) D {
+						if p.record != nil {
+							return D{}
+						}
+						btn := material.IconButton(th, &p.btnClear, icons.IconDelete, "Clear")
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			if p.record != nil {
+				return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+					return layout.Inset{
+						Top:    8,
+						Bottom: 8,
+						Left:   8,
+						Right:  8,
+					}.Layout(gtx, func(gtx C) D {
+						return p.layoutRecord(gtx, th, p.record)
+					})
+				})
+			}
+			return p.layoutRecords(gtx, th)
+		}),
+	)
+}
+
+func (p *inspectorPage) layoutRecords(gtx C, th *material.Theme) D {
+	records := inspector.Get(p.id).Records()
+	if len(records) == 0 {
+		return layout.Center.Layout(gtx, material.Body1(th, i18n.NoRecords.Value()).Layout)
+	}
+
+	if len(records) > len(p.states) {
+		states := p.states
+		p.states = make([]recordState, len(records))
+		copy(p.states, states)
+	}
+
+	return p.list.Layout(gtx, len(records), func(gtx C, index int) D {
+		r := records[index]
+
+		if p.states[index].btn.Clicked(gtx) {
+			p.selectRecord(r)
+			p.list.Position = layout.Position{}
+		}
+
+		return layout.Inset{
+			Top:    4,
+			Bottom: 4,
+			Left:   8,
+			Right:  8,
+		}.Layout(gtx, func(gtx C) D {
+			return material.ButtonLayoutStyle{
+				Background:   theme.Current().ListBg,
+				CornerRadius: 12,
+				Button:       &p.states[index].btn,
+			}.Layout(gtx, func(gtx C) D {
+				return layout.UniformInset(12).Layout(gtx, func(gtx C) D {
+					return layout.Flex{
+						Axis: layout.Vertical,
+					}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return layout.Flex{
+								Alignment: layout.Middle,
+							}.Layout(gtx,
+								layout.Rigid(func(gtx C) D {
+									label := material.Body1(th, r.Method)
+									label.Font.Weight = font.SemiBold
+									return label.Layout(gtx)
+								}),
+								layout.Rigid(layout.Spacer{Width: 8}.Layout),
+								layout.Flexed(1, func(gtx C) D {
+									label := material.Body1(th, r.URI)
+									label.MaxLines = 1
+									return label.Layout(gtx)
+								}),
+								layout.Rigid(layout.Spacer{Width: 8}.Layout),
+								layout.Rigid(func(gtx C) D {
+									label := material.Body1(th, statusText(r))
+									label.Color = statusColor(r)
+									label.Font.Weight = font.SemiBold
+									return label.Layout(gtx)
+								}),
+							)
+						}),
+						layout.Rigid(layout.Spacer{Height: 4}.Layout),
+						layout.Rigid(func(gtx C) D {
+							return layout.Flex{
+								Alignment: layout.Middle,
+								Spacing:   layout.SpaceBetween,
+							}.Layout(gtx,
+								layout.Flexed(1, material.Body2(th, r.Time.Format(time.DateTime)).Layout),
+								layout.Rigid(material.Body2(th, formatDuration(r.Duration)).Layout),
+							)
+						}),
+					)
+				})
+			})
+		})
+	})
+}
+
+func (p *inspectorPage) layoutRecord(gtx C, th *material.Theme, r *inspector.Record) D {
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return section(gtx, th, "", func(gtx C) D {
+				return layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						label := material.Body1(th, fmt.Sprintf("%s %s %s", r.Method, r.URI, r.Proto))
+						label.Font.Weight = font.SemiBold
+						return label.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: 8}.Layout),
+					layout.Rigid(func(gtx C) D {
+						return field(gtx, th, i18n.Status.Value(), statusText(r), statusColor(r))
+					}),
+					layout.Rigid(func(gtx C) D {
+						return field(gtx, th, i18n.Hostname.Value(), r.Host, th.Fg)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return field(gtx, th, i18n.Client.Value(), r.ClientIP, th.Fg)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return field(gtx, th, i18n.Time.Value(), r.Time.Format(time.DateTime), th.Fg)
+					}),
+					layout.Rigid(func(gtx C) D {
+						return field(gtx, th, i18n.Duration.Value(), formatDuration(r.Duration), th.Fg)
+					}),
+					layout.Rigid(func(gtx C) D {
+						if r.Err == "" {
+							return D{}
+						}
+						return field(gtx, th, i18n.Error.Value(), r.Err, color.NRGBA(colornames.Red500))
+					}),
+				)
+			})
+		}),
+		layout.Rigid(layout.Spacer{Height: 16}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return section(gtx, th, i18n.Request.Value(), func(gtx C) D {
+				if p.edit {
+					return p.layoutEditor(gtx, th)
+				}
+				return layoutMessage(gtx, th, &r.Request)
+			})
+		}),
+		layout.Rigid(layout.Spacer{Height: 16}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return section(gtx, th, i18n.Response.Value(), func(gtx C) D {
+				return layoutMessage(gtx, th, &r.Response)
+			})
+		}),
+	)
+}
+
+func (p *inspectorPage) layoutEditor(gtx C, th *material.Theme) D {
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			label := material.Body1(th, i18n.Headers.Value())
+			label.Font.Weight = font.SemiBold
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return p.header.Layout(gtx, th, "")
+		}),
+		layout.Rigid(layout.Spacer{Height: 8}.Layout),
+		layout.Rigid(func(gtx C) D {
+			text := i18n.Body.Value()
+			if p.record.Request.Truncated() {
+				text = fmt.Sprintf("%s (%s)", text, i18n.TruncatedBody.Value())
+			}
+			label := material.Body1(th, text)
+			label.Font.Weight = font.SemiBold
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return p.body.Layout(gtx, th, "")
+		}),
+	)
+}
+
+func (p *inspectorPage) replay() {
+	replayer, _ := tunnel.Get(p.id).(tunnel.Replayer)
+	if replayer == nil {
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Error,
+			Content: tunnel.ErrTunnelClosed.Error(),
+		})
+		return
+	}
+
+	r := *p.record
+	if p.edit {
+		r.Request.Header = parseHeader(p.header.Text())
+		// an edited body is complete, the unchanged body is still cut off if the captured one is.
+		if body := p.body.Text(); body != string(r.Request.Body) {
+			r.Request.Body = []byte(body)
+			r.Request.ContentLength = int64(len(r.Request.Body))
+		}
+	}
+
+	req, err := r.HTTPRequest()
+	if err != nil {
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Error,
+			Content: fmt.Sprintf("%s: %v", i18n.ErrReplay.Value(), err),
+		})
+		return
+	}
+
+	p.selectRecord(nil)
+
+	go func() {
+		resp, err := replayer.Replay(context.Background(), req)
+		if err != nil {
+			logger.Default().Error(err)
+			p.router.Notify(ui_widget.Message{
+				Type:    ui_widget.Error,
+				Content: fmt.Sprintf("%s: %v", i18n.ErrReplay.Value(), err),
+			})
+			return
+		}
+		resp.Body.Close()
+
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Success,
+			Content: fmt.Sprintf("%s: %s", i18n.ReplayDone.Value(), resp.Status),
+		})
+	}()
+}
+
+func parseHeader(s string) http.Header {
+	header := http.Header{}
+	for _, line := range strings.Split(s, "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if k = strings.TrimSpace(k); k == "" {
+			continue
+		}
+		header.Add(k, strings.TrimSpace(v))
+	}
+	return header
+}
+
+func layoutMessage(gtx C, th *material.Theme, m *inspector.Message) D {
+	var keys []string
+	for k := range m.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx C) D {
+			label := material.Body1(th, i18n.Headers.Value())
+			label.Font.Weight = font.SemiBold
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: 4}.Layout),
+	}
+	for _, k := range keys {
+		k := k
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return field(gtx, th, k, strings.Join(m.Header[k], ", "), th.Fg)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(layout.Spacer{Height: 8}.Layout),
+		layout.Rigid(func(gtx C) D {
+			text := fmt.Sprintf("%s (%d)", i18n.Body.Value(), m.ContentLength)
+			if m.Truncated() {
+				text = fmt.Sprintf("%s (%d, %s)", i18n.Body.Value(), m.ContentLength, i18n.TruncatedBody.Value())
+			}
+			label := material.Body1(th, text)
+			label.Font.Weight = font.SemiBold
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: 4}.Layout),
+		layout.Rigid(material.Body2(th, bodyPreview(m.Body)).Layout),
+	)
+
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx, children...)
+}
+
+func section(gtx C, th *material.Theme, title string, w layout.Widget) D {
+	return component.SurfaceStyle{
+		Theme: th,
+		ShadowStyle: component.ShadowStyle{
+			CornerRadius: 12,
+		},
+		Fill: theme.Current().ContentSurfaceBg,
+	}.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(16).Layout(gtx, func(gtx C) D {
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					if title == "" {
+						return D{}
+					}
+					return layout.Inset{Bottom: 8}.Layout(gtx, material.H6(th, title).Layout)
+				}),
+				layout.Rigid(w),
+			)
+		})
+	})
+}
+
+func field(gtx C, th *material.Theme, name, value string, c color.NRGBA) D {
+	return layout.Inset{
+		Top:    2,
+		Bottom: 2,
+	}.Layout(gtx, func(gtx C) D {
+		return layout.Flex{}.Layout(gtx,
+			layout.Flexed(0.3, material.Body2(th, name).Layout),
+			layout.Rigid(layout.Spacer{Width: 8}.Layout),
+			layout.Flexed(0.7, func(gtx C) D {
+				label := material.Body2(th, value)
+				label.Color = c
+				return label.Layout(gtx)
+			}),
+		)
+	})
+}
+
+func statusText(r *inspector.Record) string {
+	if r.StatusCode == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+}
+
+func statusColor(r *inspector.Record) color.NRGBA {
+	switch {
+	case r.StatusCode >= 500 || r.StatusCode == 0:
+		return color.NRGBA(colornames.Red500)
+	case r.StatusCode >= 400:
+		return color.NRGBA(colornames.Orange500)
+	case r.StatusCode >= 300:
+		return color.NRGBA(colornames.Blue500)
+	default:
+		return color.NRGBA(colornames.Green500)
+	}
+}
+
+func bodyPreview(body []byte) string {
+	if len(body) == 0 {
+		return i18n.EmptyBody.Value()
+	}
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("%s, %d bytes", i18n.BinaryBody.Value(), len(body))
+	}
+	if len(body) > maxBodyPreview {
+		return strings.ToValidUTF8(string(body[:maxBodyPreview]), "") + "..."
+	}
+	return string(body)
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	default:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	}
+}
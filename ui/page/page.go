@@ -10,18 +10,24 @@ type PagePath string
 const (
 	PageHome     PagePath = "/"
 	PageSettings PagePath = "/settings"
-
-	PageTunnel     PagePath = "/tunnel"
-	PageTunnelFile PagePath = "/tunnel/file"
-	PageTunnelHTTP PagePath = "/tunnel/http"
-	PageTunnelTCP  PagePath = "/tunnel/tcp"
-	PageTunnelUDP  PagePath = "/tunnel/udp"
-
-	PageEntrypoint    PagePath = "/entrypoint"
-	PageEntrypointTCP PagePath = "/entrypoint/tcp"
-	PageEntrypointUDP PagePath = "/entrypoint/udp"
+	PageServer   PagePath = "/server"
+
+	PageTunnel         PagePath = "/tunnel"
+	PageTunnelFile     PagePath = "/tunnel/file"
+	PageTunnelHTTP     PagePath = "/tunnel/http"
+	PageTunnelTCP      PagePath = "/tunnel/tcp"
+	PageTunnelUDP      PagePath = "/tunnel/udp"
+	PageTunnelProxy    PagePath = "/tunnel/proxy"
+	PageTunnelAdvanced PagePath = "/tunnel/advanced"
+
+	PageEntrypoint     PagePath = "/entrypoint"
+	PageEntrypointTCP  PagePath = "/entrypoint/tcp"
+	PageEntrypointUDP  PagePath = "/entrypoint/udp"
+	PageEntrypointHTTP PagePath = "/entrypoint/http"
+	PageEntrypointFile PagePath = "/entrypoint/file"
 
 	PageInspector PagePath = "/inspector"
+	PageShare     PagePath = "/share"
 )
 
 type PageOptions struct {
@@ -1,6 +1,7 @@
 package page
 
 import (
+	"io"
 	"time"
 
 	"gioui.org/app"
@@ -13,6 +14,7 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"gioui.org/x/explorer"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/gost.plus/ui/theme"
 	ui_widget "github.com/go-gost/gost.plus/ui/widget"
@@ -36,6 +38,8 @@ type Router struct {
 	modal        *component.ModalLayer
 	notification *ui_widget.Notification
 	events       chan Event
+	dialogs      chan *ui_widget.Dialog
+	explorer     *explorer.Explorer
 }
 
 func NewRouter(w *app.Window, th *T) *Router {
@@ -47,7 +51,9 @@ func NewRouter(w *app.Window, th *T) *Router {
 		notification: ui_widget.NewNotification(3*time.Second, func() {
 			w.Invalidate()
 		}),
-		events: make(chan Event, 16),
+		events:   make(chan Event, 16),
+		dialogs:  make(chan *ui_widget.Dialog, 1),
+		explorer: explorer.NewExplorer(w),
 	}
 
 	return r
@@ -114,6 +120,19 @@ func (r *Router) Layout(gtx C) D {
 
 	r.Theme.Palette = theme.Current().Material
 
+	select {
+	case dialog := <-r.dialogs:
+		clicked := dialog.Clicked
+		dialog.Clicked = func(ok bool) {
+			r.modal.Disappear(time.Now())
+			if clicked != nil {
+				clicked(ok)
+			}
+		}
+		r.ShowModal(gtx, dialog.Layout)
+	default:
+	}
+
 	defer r.modal.Layout(gtx, r.Theme)
 
 	return layout.Background{}.Layout(gtx,
@@ -184,6 +203,18 @@ func (r *Router) HideModal(gtx C) {
 	r.modal.Disappear(gtx.Now)
 }
 
+// ShowDialog shows the dialog on the next frame, it can be called from another goroutine.
+// The dialog is hidden when a button is clicked. It reports false if another dialog is pending.
+func (r *Router) ShowDialog(dialog *ui_widget.Dialog) bool {
+	select {
+	case r.dialogs <- dialog:
+		r.w.Invalidate()
+		return true
+	default:
+		return false
+	}
+}
+
 func (r *Router) Notify(message ui_widget.Message) {
 	r.notification.Show(message)
 }
@@ -199,6 +230,21 @@ func (r *Router) Event() <-chan Event {
 	return r.events
 }
 
+// ListenEvents passes the window events to the file chooser, it must be called for every window event.
+func (r *Router) ListenEvents(evt event.Event) {
+	r.explorer.ListenEvents(evt)
+}
+
+// ChooseFile shows the file chooser of the platform, it blocks until the file is chosen.
+func (r *Router) ChooseFile(extensions ...string) (io.ReadCloser, error) {
+	return r.explorer.ChooseFile(extensions...)
+}
+
+// Invalidate requests a redraw of the window, e.g. after a change from another goroutine.
+func (r *Router) Invalidate() {
+	r.w.Invalidate()
+}
+
 type routeStack struct {
 	routes []Route
 }
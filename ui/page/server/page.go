@@ -0,0 +1,419 @@
+package server
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/server"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/icons"
+	"github.com/go-gost/gost.plus/ui/page"
+	"github.com/go-gost/gost.plus/ui/theme"
+	ui_widget "github.com/go-gost/gost.plus/ui/widget"
+)
+
+type C = layout.Context
+type D = layout.Dimensions
+
+type serverPage struct {
+	router *page.Router
+	menu   ui_widget.Menu
+	list   widget.List
+
+	btnBack widget.Clickable
+	btnSave widget.Clickable
+
+	enabled    ui_widget.Switcher
+	addr       component.TextField
+	entrypoint component.TextField
+
+	transport     ui_widget.Selector
+	transportPath component.TextField
+
+	cert     *page.FileField
+	key      *page.FileField
+	clientCA *page.FileField
+
+	ingress component.TextField
+}
+
+func NewPage(r *page.Router) page.Page {
+	return &serverPage{
+		router: r,
+		list: widget.List{
+			List: layout.List{
+				Axis: layout.Vertical,
+			},
+		},
+		addr: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
+		entrypoint: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
+		transport: ui_widget.Selector{Title: i18n.Transport},
+		transportPath: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
+		cert:     page.NewFileField(),
+		key:      page.NewFileField(),
+		clientCA: page.NewFileField(),
+	}
+}
+
+func (p *serverPage) Init(opts ...page.PageOption) {
+	cfg := config.Get().Server
+	if cfg == nil {
+		cfg = &config.ServerConfig{}
+	}
+
+	p.enabled.SetValue(cfg.Addr != "")
+	p.addr.SetText(cfg.Addr)
+	p.entrypoint.SetText(cfg.Entrypoint)
+
+	transport := config.Transport{Type: tunnel.DefaultTransport}
+	if cfg.Transport != nil && cfg.Transport.Type != "" {
+		transport = *cfg.Transport
+	}
+	p.transport.Clear()
+	p.transport.Select(ui_widget.SelectorItem{Name: strings.ToUpper(transport.Type), Value: transport.Type})
+	p.transportPath.SetText(transport.Path)
+
+	p.cert.SetText(cfg.Cert)
+	p.key.SetText(cfg.Key)
+	p.clientCA.SetText(cfg.ClientCA)
+
+	var rules []string
+	for _, rule := range cfg.Ingress {
+		rules = append(rules, rule.Hostname+" "+rule.Tunnel)
+	}
+	p.ingress.SetText(strings.Join(rules, "\n"))
+}
+
+func (p *serverPage) Destroy() {
+
+}
+
+func (p *serverPage) Layout(gtx C) D {
+	if p.btnBack.Clicked(gtx) {
+		p.router.Back()
+	}
+
+	if p.btnSave.Clicked(gtx) {
+		p.save()
+	}
+
+	th := p.router.Theme
+
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		// header
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{
+				Top:    8,
+				Bottom: 8,
+				Left:   8,
+				Right:  8,
+			}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						btn := material.IconButton(th, &p.btnBack, icons.IconBack, "Back")
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: 8}.Layout),
+					layout.Flexed(1, material.H6(th, i18n.SelfHostedServer.Value()).Layout),
+					layout.Rigid(layout.Spacer{Width: 8}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if !p.changed() {
+							return D{}
+						}
+						btn := material.IconButton(th, &p.btnSave, icons.IconDone, "Done")
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+				return layout.Inset{
+					Top:    8,
+					Bottom: 8,
+					Left:   8,
+					Right:  8,
+				}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{
+						Axis: layout.Vertical,
+					}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return surface(gtx, th, func(gtx C) D {
+								return p.layoutConfig(gtx, th)
+							})
+						}),
+						layout.Rigid(layout.Spacer{Height: 16}.Layout),
+						layout.Rigid(func(gtx C) D {
+							if !server.Running() {
+								return D{}
+							}
+							return surface(gtx, th, func(gtx C) D {
+								return ui_widget.ServerTunnelList(gtx, th, server.Tunnels())
+							})
+						}),
+					)
+				})
+			})
+		}),
+	)
+}
+
+func (p *serverPage) layoutConfig(gtx C, th *material.Theme) D {
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			p.enabled.Title = i18n.EnableServer.Value()
+			return p.enabled.Layout(gtx, th)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if !p.enabled.Value() {
+				return D{}
+			}
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					fp := server.Fingerprint()
+					if fp == "" {
+						return D{}
+					}
+					return layout.Inset{Top: 8, Bottom: 8}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{
+							Axis: layout.Vertical,
+						}.Layout(gtx,
+							layout.Rigid(material.Body2(th, i18n.ServerFingerprint.Value()).Layout),
+							layout.Rigid(layout.Spacer{Height: 4}.Layout),
+							layout.Rigid(material.Caption(th, tunnel.FormatFingerprint(fp)).Layout),
+						)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					return p.addr.Layout(gtx, th, i18n.ListenAddr.Value()+", "+server.DefaultAddr)
+				}),
+				layout.Rigid(layout.Spacer{Height: 8}.Layout),
+				layout.Rigid(func(gtx C) D {
+					if p.transport.Clicked(gtx) {
+						p.showTransportMenu(gtx)
+					}
+					return p.transport.Layout(gtx, th)
+				}),
+				layout.Rigid(func(gtx C) D {
+					switch p.transport.Item().Value {
+					case tunnel.TransportWS, tunnel.TransportWSS, tunnel.TransportH2, tunnel.TransportGRPC:
+					default:
+						p.transportPath.Clear()
+						return D{}
+					}
+					return p.transportPath.Layout(gtx, th, i18n.TransportPath.Value())
+				}),
+				layout.Rigid(func(gtx C) D {
+					return p.entrypoint.Layout(gtx, th, i18n.EntrypointAddr.Value()+", "+server.DefaultEntrypoint)
+				}),
+				layout.Rigid(func(gtx C) D {
+					switch p.transport.Item().Value {
+					case tunnel.TransportWS, tunnel.TransportKCP:
+						return D{}
+					}
+					return layout.Flex{
+						Axis: layout.Vertical,
+					}.Layout(gtx,
+						layout.Rigid(layout.Spacer{Height: 8}.Layout),
+						layout.Rigid(material.Body1(th, i18n.ServerCertificate.Value()).Layout),
+						layout.Rigid(func(gtx C) D {
+							return p.cert.Layout(gtx, th, p.router, i18n.ServerCert.Value())
+						}),
+						layout.Rigid(func(gtx C) D {
+							return p.key.Layout(gtx, th, p.router, i18n.ServerKey.Value())
+						}),
+						layout.Rigid(func(gtx C) D {
+							return p.clientCA.Layout(gtx, th, p.router, i18n.ClientCA.Value())
+						}),
+					)
+				}),
+				layout.Rigid(layout.Spacer{Height: 8}.Layout),
+				layout.Rigid(material.Body1(th, i18n.IngressRules.Value()).Layout),
+				layout.Rigid(func(gtx C) D {
+					if _, err := p.ingressRules(); err != nil {
+						p.ingress.SetError(err.Error())
+					} else {
+						p.ingress.ClearError()
+					}
+					return p.ingress.Layout(gtx, th, i18n.IngressHint.Value())
+				}),
+			)
+		}),
+	)
+}
+
+// ingressRules parses the rules from the input, one "hostname tunnel-ID" per line.
+func (p *serverPage) ingressRules() ([]config.IngressRule, error) {
+	var rules []config.IngressRule
+	for _, line := range strings.Split(p.ingress.Text(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, errors.New(i18n.ErrInvalidIngress.Value())
+		}
+		rules = append(rules, config.IngressRule{Hostname: fields[0], Tunnel: fields[1]})
+	}
+	return rules, nil
+}
+
+// serverConfig returns the server config from the input, it is nil if the server is disabled.
+func (p *serverPage) serverConfig() (*config.ServerConfig, error) {
+	if !p.enabled.Value() {
+		return nil, nil
+	}
+	cfg := &config.ServerConfig{
+		Addr:       strings.TrimSpace(p.addr.Text()),
+		Entrypoint: strings.TrimSpace(p.entrypoint.Text()),
+		Transport: &config.Transport{
+			Type: p.transport.Item().Value,
+			Path: strings.TrimSpace(p.transportPath.Text()),
+		},
+		Cert:     strings.TrimSpace(p.cert.Text()),
+		Key:      strings.TrimSpace(p.key.Text()),
+		ClientCA: strings.TrimSpace(p.clientCA.Text()),
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = server.DefaultAddr
+	}
+	if cfg.Entrypoint == "" {
+		cfg.Entrypoint = server.DefaultEntrypoint
+	}
+	switch cfg.Transport.Type {
+	case tunnel.TransportWS, tunnel.TransportKCP:
+		cfg.Cert, cfg.Key, cfg.ClientCA = "", "", ""
+	}
+	rules, err := p.ingressRules()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Ingress = rules
+	return cfg, nil
+}
+
+func (p *serverPage) changed() bool {
+	cfg, err := p.serverConfig()
+	if err != nil {
+		return true
+	}
+	current := config.Get().Server
+	if current != nil && current.Addr == "" {
+		current = nil
+	}
+	return !reflect.DeepEqual(cfg, current)
+}
+
+func (p *serverPage) save() {
+	cfg, err := p.serverConfig()
+	if err == nil {
+		err = server.Validate(cfg)
+	}
+	if err != nil {
+		p.notifyError(err)
+		return
+	}
+
+	c := config.Get()
+	if cfg == nil && c.Server != nil {
+		// keep the rest of the config for enabling the server again.
+		c.Server.Addr = ""
+	} else {
+		c.Server = cfg
+	}
+	config.Set(c)
+	c.Write()
+
+	if err := server.Start(); err != nil {
+		p.notifyError(err)
+		return
+	}
+	p.Init()
+
+	p.router.Notify(ui_widget.Message{
+		Type:    ui_widget.Success,
+		Content: i18n.SettingsApplied.Value(),
+	})
+}
+
+func (p *serverPage) notifyError(err error) {
+	p.router.Notify(ui_widget.Message{
+		Type:    ui_widget.Error,
+		Content: err.Error(),
+	})
+}
+
+func (p *serverPage) showTransportMenu(gtx C) {
+	var options []ui_widget.MenuOption
+	for _, t := range tunnel.Transports {
+		options = append(options, ui_widget.MenuOption{
+			Name:     strings.ToUpper(t),
+			Value:    t,
+			Selected: p.transport.AnyValue(t),
+		})
+	}
+
+	p.menu.Title = i18n.Transport
+	p.menu.Options = options
+	p.menu.OnClick = func(ok bool) {
+		p.router.HideModal(gtx)
+		if !ok {
+			return
+		}
+
+		for _, opt := range p.menu.Options {
+			if opt.Selected {
+				p.transport.Clear()
+				p.transport.Select(ui_widget.SelectorItem{Name: opt.Name, Value: opt.Value})
+				break
+			}
+		}
+	}
+
+	p.router.ShowModal(gtx, func(gtx page.C, th *material.Theme) page.D {
+		return p.menu.Layout(gtx, th)
+	})
+}
+
+func surface(gtx C, th *material.Theme, w layout.Widget) D {
+	return component.SurfaceStyle{
+		Theme: th,
+		ShadowStyle: component.ShadowStyle{
+			CornerRadius: 12,
+		},
+		Fill: theme.Current().ContentSurfaceBg,
+	}.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(16).Layout(gtx, w)
+	})
+}
@@ -1,12 +1,23 @@
 package settings
 
 import (
+	"errors"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
 	"gioui.org/font"
 	"gioui.org/layout"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/api"
 	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/metrics"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/entrypoint"
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/icons"
 	"github.com/go-gost/gost.plus/ui/page"
@@ -21,9 +32,31 @@ type settingsPage struct {
 	list   widget.List
 
 	btnBack widget.Clickable
+	btnSave widget.Clickable
 
 	lang  ui_widget.Selector
 	theme ui_widget.Selector
+
+	server     component.TextField
+	entrypoint component.TextField
+
+	transport        ui_widget.Selector
+	transportPath    component.TextField
+	transportOptions component.TextField
+
+	proxies     proxyList
+	systemProxy ui_widget.Switcher
+
+	serverCA   *page.FileField
+	serverCert *page.FileField
+	serverKey  *page.FileField
+	serverPins component.TextField
+
+	api     ui_widget.Switcher
+	apiAddr component.TextField
+
+	metrics     ui_widget.Switcher
+	metricsAddr component.TextField
 }
 
 func NewPage(r *page.Router) page.Page {
@@ -36,6 +69,43 @@ func NewPage(r *page.Router) page.Page {
 		},
 		lang:  ui_widget.Selector{Title: i18n.Language},
 		theme: ui_widget.Selector{Title: i18n.Theme},
+		server: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
+		entrypoint: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
+		transport: ui_widget.Selector{Title: i18n.Transport},
+		transportPath: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
+		transportOptions: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
+		systemProxy: ui_widget.Switcher{Title: i18n.SystemProxy.Value()},
+		serverCA:    page.NewFileField(),
+		serverCert:  page.NewFileField(),
+		serverKey:   page.NewFileField(),
+		api:         ui_widget.Switcher{Title: i18n.ControlAPI.Value()},
+		apiAddr: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
+		metrics: ui_widget.Switcher{Title: i18n.MetricsExporter.Value()},
+		metricsAddr: component.TextField{
+			Editor: widget.Editor{
+				SingleLine: true,
+			},
+		},
 	}
 }
 
@@ -54,6 +124,37 @@ func (p *settingsPage) Init(opts ...page.PageOption) {
 		Value: i18n.Current().Value,
 	})
 
+	p.server.SetText(settings.Server)
+	p.entrypoint.SetText(settings.Entrypoint)
+
+	transport := tunnel.TransportOf(nil)
+	p.transport.Clear()
+	p.transport.Select(ui_widget.SelectorItem{Name: strings.ToUpper(transport.Type), Value: transport.Type})
+	p.transportPath.SetText(transport.Path)
+	p.transportOptions.SetText(formatMetadata(transport.Metadata))
+	p.proxies.Set(settings.Proxies)
+	p.systemProxy.SetValue(settings.SystemProxy)
+
+	serverTLS := tunnel.ServerTLSOf()
+	p.serverCA.SetText(serverTLS.CA)
+	p.serverCert.SetText(serverTLS.Cert)
+	p.serverKey.SetText(serverTLS.Key)
+	var pins []string
+	for _, pin := range serverTLS.Pins {
+		pins = append(pins, tunnel.FormatFingerprint(pin))
+	}
+	p.serverPins.SetText(strings.Join(pins, "\n"))
+
+	apiAddr := ""
+	if cfg := config.Get().API; cfg != nil {
+		apiAddr = cfg.Addr
+	}
+	p.api.SetValue(apiAddr != "")
+	p.apiAddr.SetText(apiAddr)
+
+	p.metrics.SetValue(metricsAddr() != "")
+	p.metricsAddr.SetText(metricsAddr())
+
 	p.theme.Clear()
 	switch settings.Theme {
 	case theme.Light:
@@ -83,6 +184,10 @@ func (p *settingsPage) Layout(gtx layout.Context) layout.Dimensions {
 		p.router.Back()
 	}
 
+	if p.btnSave.Clicked(gtx) {
+		p.save()
+	}
+
 	th := p.router.Theme
 
 	return layout.Flex{
@@ -106,11 +211,20 @@ func (p *settingsPage) Layout(gtx layout.Context) layout.Dimensions {
 						return btn.Layout(gtx)
 					}),
 					layout.Rigid(layout.Spacer{Width: 8}.Layout),
-					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 						title := material.H6(th, i18n.Settings.Value())
 						return title.Layout(gtx)
 					}),
 					layout.Rigid(layout.Spacer{Width: 8}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !p.changed() {
+							return layout.Dimensions{}
+						}
+						btn := material.IconButton(th, &p.btnSave, icons.IconDone, "Done")
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
 				)
 			})
 		}),
@@ -187,9 +301,434 @@ func (p *settingsPage) layout(gtx layout.Context, th *material.Theme) layout.Dim
 				})
 			})
 		}),
+		layout.Rigid(layout.Spacer{Height: 16}.Layout),
+
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return component.SurfaceStyle{
+				Theme: th,
+				ShadowStyle: component.ShadowStyle{
+					CornerRadius: 12,
+				},
+				Fill: theme.Current().ContentSurfaceBg,
+			}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(16).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Axis: layout.Vertical,
+					}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return material.Body1(th, i18n.Server.Value()).Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if err := validateServer(p.server.Text()); err != nil {
+								p.server.SetError(err.Error())
+							} else {
+								p.server.ClearError()
+							}
+							return p.server.Layout(gtx, th, tunnel.DefaultServerName)
+						}),
+						layout.Rigid(layout.Spacer{Height: 8}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if p.transport.Clicked(gtx) {
+								p.showTransportMenu(gtx)
+							}
+							return p.transport.Layout(gtx, th)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							switch p.transport.Item().Value {
+							case tunnel.TransportWS, tunnel.TransportWSS, tunnel.TransportH2, tunnel.TransportGRPC:
+							default:
+								p.transportPath.Clear()
+								return layout.Dimensions{}
+							}
+							if path := strings.TrimSpace(p.transportPath.Text()); path != "" && !strings.HasPrefix(path, "/") {
+								p.transportPath.SetError(i18n.ErrInvalidPath.Value())
+							} else {
+								p.transportPath.ClearError()
+							}
+							return p.transportPath.Layout(gtx, th, i18n.TransportPath.Value())
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return p.transportOptions.Layout(gtx, th, i18n.TransportOptions.Value())
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return p.proxies.Layout(gtx, th)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							p.systemProxy.Title = i18n.SystemProxy.Value()
+							return p.systemProxy.Layout(gtx, th)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							switch p.transport.Item().Value {
+							case tunnel.TransportWS, tunnel.TransportKCP:
+								return layout.Dimensions{}
+							}
+							return p.layoutServerTLS(gtx, th)
+						}),
+						layout.Rigid(layout.Spacer{Height: 16}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return material.Body1(th, i18n.PublicEntrypoint.Value()).Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return p.entrypoint.Layout(gtx, th, tunnel.DefaultEndpointAddr)
+						}),
+					)
+				})
+			})
+		}),
+		layout.Rigid(layout.Spacer{Height: 16}.Layout),
+
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return component.SurfaceStyle{
+				Theme: th,
+				ShadowStyle: component.ShadowStyle{
+					CornerRadius: 12,
+				},
+				Fill: theme.Current().ContentSurfaceBg,
+			}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(16).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Axis: layout.Vertical,
+					}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							p.api.Title = i18n.ControlAPI.Value()
+							return p.api.Layout(gtx, th)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if !p.api.Value() {
+								return layout.Dimensions{}
+							}
+							if err := validateAPIAddr(p.apiAddr.Text()); err != nil {
+								p.apiAddr.SetError(err.Error())
+							} else {
+								p.apiAddr.ClearError()
+							}
+							return p.apiAddr.Layout(gtx, th, api.DefaultAddr)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							cfg := config.Get().API
+							if !p.api.Value() || cfg == nil || cfg.Token == "" {
+								return layout.Dimensions{}
+							}
+							return layout.Inset{Top: 8}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return layout.Flex{
+									Axis: layout.Vertical,
+								}.Layout(gtx,
+									layout.Rigid(material.Body2(th, i18n.Token.Value()).Layout),
+									layout.Rigid(layout.Spacer{Height: 4}.Layout),
+									layout.Rigid(material.Body2(th, cfg.Token).Layout),
+								)
+							})
+						}),
+						layout.Rigid(layout.Spacer{Height: 8}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							p.metrics.Title = i18n.MetricsExporter.Value()
+							return p.metrics.Layout(gtx, th)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if !p.metrics.Value() {
+								return layout.Dimensions{}
+							}
+							if err := validateAddr(p.metricsAddr.Text()); err != nil {
+								p.metricsAddr.SetError(err.Error())
+							} else {
+								p.metricsAddr.ClearError()
+							}
+							return p.metricsAddr.Layout(gtx, th, metrics.DefaultAddr)
+						}),
+					)
+				})
+			})
+		}),
+	)
+}
+
+func (p *settingsPage) changed() bool {
+	settings := config.Get().Settings
+	if settings == nil {
+		settings = &config.Settings{}
+	}
+	return strings.TrimSpace(p.server.Text()) != settings.Server ||
+		strings.TrimSpace(p.entrypoint.Text()) != settings.Entrypoint ||
+		!reflect.DeepEqual(p.transportConfig(), tunnel.TransportOf(nil)) ||
+		p.proxiesChanged(settings) ||
+		p.serverTLSChanged(settings) ||
+		p.apiAddress() != apiAddr() ||
+		p.metricsAddress() != metricsAddr()
+}
+
+func (p *settingsPage) proxiesChanged(settings *config.Settings) bool {
+	proxies, err := p.proxies.Proxies()
+	return err != nil ||
+		!reflect.DeepEqual(proxies, settings.Proxies) ||
+		p.systemProxy.Value() != settings.SystemProxy
+}
+
+func (p *settingsPage) serverTLSChanged(settings *config.Settings) bool {
+	serverTLS, err := p.serverTLS()
+	return err != nil || !reflect.DeepEqual(serverTLS, settings.ServerTLS)
+}
+
+// serverTLS returns the TLS to the tunnel server from the input, it is nil if nothing is set.
+func (p *settingsPage) serverTLS() (*config.ServerTLS, error) {
+	t := &config.ServerTLS{
+		CA:   strings.TrimSpace(p.serverCA.Text()),
+		Cert: strings.TrimSpace(p.serverCert.Text()),
+		Key:  strings.TrimSpace(p.serverKey.Text()),
+	}
+	for _, s := range strings.Fields(p.serverPins.Text()) {
+		pin, err := tunnel.ParsePin(s)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(t.Pins, pin) {
+			t.Pins = append(t.Pins, pin)
+		}
+	}
+	if reflect.DeepEqual(t, &config.ServerTLS{}) {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func (p *settingsPage) layoutServerTLS(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		layout.Rigid(layout.Spacer{Height: 8}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Body1(th, i18n.ServerCertificate.Value()).Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return p.serverCA.Layout(gtx, th, p.router, i18n.TLSCA.Value())
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return p.serverCert.Layout(gtx, th, p.router, i18n.TLSCert.Value())
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return p.serverKey.Layout(gtx, th, p.router, i18n.TLSKey.Value())
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if _, err := p.serverTLS(); err != nil {
+				p.serverPins.SetError(i18n.ErrInvalidPin.Value())
+			} else {
+				p.serverPins.ClearError()
+			}
+			return p.serverPins.Layout(gtx, th, i18n.ServerPins.Value())
+		}),
 	)
 }
 
+// apiAddress returns the control API address from the input, it is empty if the API is disabled.
+func (p *settingsPage) apiAddress() string {
+	if !p.api.Value() {
+		return ""
+	}
+	if addr := strings.TrimSpace(p.apiAddr.Text()); addr != "" {
+		return addr
+	}
+	return api.DefaultAddr
+}
+
+func apiAddr() string {
+	if cfg := config.Get().API; cfg != nil {
+		return cfg.Addr
+	}
+	return ""
+}
+
+// metricsAddress returns the metrics exporter address from the input, it is empty if the exporter is disabled.
+func (p *settingsPage) metricsAddress() string {
+	if !p.metrics.Value() {
+		return ""
+	}
+	if addr := strings.TrimSpace(p.metricsAddr.Text()); addr != "" {
+		return addr
+	}
+	return metrics.DefaultAddr
+}
+
+func metricsAddr() string {
+	if cfg := config.Get().Metrics; cfg != nil {
+		return cfg.Addr
+	}
+	return ""
+}
+
+// transportConfig returns the transport from the input.
+func (p *settingsPage) transportConfig() config.Transport {
+	return config.Transport{
+		Type:     p.transport.Item().Value,
+		Path:     strings.TrimSpace(p.transportPath.Text()),
+		Metadata: parseMetadata(p.transportOptions.Text()),
+	}
+}
+
+func (p *settingsPage) save() {
+	server := strings.TrimSpace(p.server.Text())
+	if err := validateServer(server); err != nil {
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Error,
+			Content: err.Error(),
+		})
+		return
+	}
+	transport := p.transportConfig()
+	proxies, err := p.proxies.Proxies()
+	if err == nil {
+		err = tunnel.ValidateTransport(&transport)
+	}
+	if err == nil && (len(proxies) > 0 || p.systemProxy.Value()) {
+		err = tunnel.ValidateProxyTransport(transport.Type)
+	}
+	var serverTLS *config.ServerTLS
+	if err == nil {
+		serverTLS, err = p.serverTLS()
+	}
+	if err == nil {
+		err = tunnel.ValidateServerTLS(serverTLS)
+	}
+	if err != nil {
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Error,
+			Content: err.Error(),
+		})
+		return
+	}
+	addr := p.apiAddress()
+	mAddr := p.metricsAddress()
+	if err := validateAPIAddr(addr); err != nil {
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Error,
+			Content: err.Error(),
+		})
+		return
+	}
+	if err := validateAddr(mAddr); err != nil {
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Error,
+			Content: err.Error(),
+		})
+		return
+	}
+
+	cfg := config.Get()
+	if cfg.Settings == nil {
+		cfg.Settings = &config.Settings{}
+	}
+	reload := server != cfg.Settings.Server ||
+		strings.TrimSpace(p.entrypoint.Text()) != cfg.Settings.Entrypoint ||
+		!reflect.DeepEqual(transport, tunnel.TransportOf(nil)) ||
+		p.proxiesChanged(cfg.Settings) ||
+		p.serverTLSChanged(cfg.Settings)
+	cfg.Settings.Server = server
+	cfg.Settings.Entrypoint = strings.TrimSpace(p.entrypoint.Text())
+	cfg.Settings.Transport = &transport
+	cfg.Settings.Proxies = proxies
+	cfg.Settings.SystemProxy = p.systemProxy.Value()
+	cfg.Settings.ServerTLS = serverTLS
+
+	restartAPI := addr != apiAddr()
+	if addr != "" {
+		if cfg.API == nil {
+			cfg.API = &config.APIConfig{}
+		}
+		cfg.API.Addr = addr
+	} else if cfg.API != nil {
+		cfg.API.Addr = ""
+	}
+
+	restartMetrics := mAddr != metricsAddr()
+	if mAddr != "" {
+		if cfg.Metrics == nil {
+			cfg.Metrics = &config.MetricsConfig{}
+		}
+		cfg.Metrics.Addr = mAddr
+	} else if cfg.Metrics != nil {
+		cfg.Metrics.Addr = ""
+	}
+
+	config.Set(cfg)
+	cfg.Write()
+
+	if reload {
+		tunnel.Reload()
+		entrypoint.Reload()
+		tunnel.SaveConfig()
+		entrypoint.SaveConfig()
+	}
+
+	if restartAPI {
+		if err := api.Start(); err != nil {
+			p.router.Notify(ui_widget.Message{
+				Type:    ui_widget.Error,
+				Content: err.Error(),
+			})
+			return
+		}
+		p.apiAddr.SetText(addr)
+	}
+
+	if restartMetrics {
+		if err := metrics.Start(); err != nil {
+			p.router.Notify(ui_widget.Message{
+				Type:    ui_widget.Error,
+				Content: err.Error(),
+			})
+			return
+		}
+		p.metricsAddr.SetText(mAddr)
+	}
+
+	p.router.Notify(ui_widget.Message{
+		Type:    ui_widget.Success,
+		Content: i18n.SettingsApplied.Value(),
+	})
+}
+
+func validateAddr(addr string) error {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return nil
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.New(i18n.ErrInvalidAddr.Value())
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return errors.New(i18n.ErrInvalidAddr.Value())
+	}
+	return nil
+}
+
+// validateAPIAddr checks the address of the control API, it only listens on a loopback address.
+func validateAPIAddr(addr string) error {
+	if err := validateAddr(addr); err != nil {
+		return err
+	}
+	if addr = strings.TrimSpace(addr); addr != "" && tunnel.ValidateLoopback(addr) != nil {
+		return errors.New(i18n.ErrLoopbackAddr.Value())
+	}
+	return nil
+}
+
+func validateServer(server string) error {
+	server = strings.TrimSpace(server)
+	if server == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, tunnel.DefaultServerPort)
+	}
+	host, port, err := net.SplitHostPort(server)
+	if err != nil || host == "" {
+		return errors.New(i18n.ErrInvalidAddr.Value())
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return errors.New(i18n.ErrInvalidAddr.Value())
+	}
+	return nil
+}
+
 func (p *settingsPage) showLangMenu(gtx layout.Context) {
 	var options []ui_widget.MenuOption
 	for _, lang := range i18n.Langs() {
@@ -247,6 +786,63 @@ func (p *settingsPage) showLangMenu(gtx layout.Context) {
 	})
 }
 
+func (p *settingsPage) showTransportMenu(gtx layout.Context) {
+	var options []ui_widget.MenuOption
+	for _, t := range tunnel.Transports {
+		options = append(options, ui_widget.MenuOption{
+			Name:     strings.ToUpper(t),
+			Value:    t,
+			Selected: p.transport.AnyValue(t),
+		})
+	}
+
+	p.menu.Title = i18n.Transport
+	p.menu.Options = options
+	p.menu.OnClick = func(ok bool) {
+		p.router.HideModal(gtx)
+		if !ok {
+			return
+		}
+
+		for _, opt := range p.menu.Options {
+			if opt.Selected {
+				p.transport.Clear()
+				p.transport.Select(ui_widget.SelectorItem{Name: opt.Name, Value: opt.Value})
+				break
+			}
+		}
+	}
+
+	p.router.ShowModal(gtx, func(gtx page.C, th *material.Theme) page.D {
+		return p.menu.Layout(gtx, th)
+	})
+}
+
+// parseMetadata parses the comma-separated key=value pairs.
+func parseMetadata(s string) map[string]string {
+	var md map[string]string
+	for _, kv := range strings.Split(s, ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		if k = strings.TrimSpace(k); k == "" {
+			continue
+		}
+		if md == nil {
+			md = make(map[string]string)
+		}
+		md[k] = strings.TrimSpace(v)
+	}
+	return md
+}
+
+func formatMetadata(md map[string]string) string {
+	var kvs []string
+	for k, v := range md {
+		kvs = append(kvs, k+"="+v)
+	}
+	slices.Sort(kvs)
+	return strings.Join(kvs, ",")
+}
+
 func (p *settingsPage) showThemeMenu(gtx layout.Context) {
 	options := []ui_widget.MenuOption{
 		{Key: i18n.ThemeSystem, Value: theme.System},
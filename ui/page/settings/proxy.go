@@ -0,0 +1,140 @@
+package settings
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/icons"
+	"github.com/go-gost/gost.plus/ui/page"
+	"github.com/go-gost/gost.plus/ui/theme"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+)
+
+// proxy is the editor of an upstream proxy, the password is kept out of the URL.
+type proxy struct {
+	url       component.TextField
+	password  component.TextField
+	btnDelete widget.Clickable
+}
+
+func newProxy(p config.Proxy) *proxy {
+	v := &proxy{
+		url:      component.TextField{Editor: widget.Editor{SingleLine: true}},
+		password: component.TextField{Editor: widget.Editor{SingleLine: true, Mask: '*'}},
+	}
+	if p.Addr != "" {
+		v.url.SetText(tunnel.FormatProxy(p))
+	}
+	v.password.SetText(p.Password)
+	return v
+}
+
+func (p *proxy) Proxy() (config.Proxy, error) {
+	v, err := tunnel.ParseProxy(p.url.Text())
+	if password := p.password.Text(); password != "" {
+		v.Password = password
+	}
+	return v, err
+}
+
+func (p *proxy) Layout(gtx page.C, th *material.Theme) page.D {
+	return layout.Flex{
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Flexed(2, func(gtx page.C) page.D {
+			if _, err := p.Proxy(); err != nil && strings.TrimSpace(p.url.Text()) != "" {
+				p.url.SetError(i18n.ErrInvalidProxy.Value())
+			} else {
+				p.url.ClearError()
+			}
+			return p.url.Layout(gtx, th, i18n.ProxyURL.Value())
+		}),
+		layout.Rigid(layout.Spacer{Width: 8}.Layout),
+		layout.Flexed(1, func(gtx page.C) page.D {
+			return p.password.Layout(gtx, th, i18n.Password.Value())
+		}),
+		layout.Rigid(func(gtx page.C) page.D {
+			btn := material.IconButton(th, &p.btnDelete, icons.IconRemove, "Delete")
+			btn.Color = color.NRGBA(colornames.Red500)
+			btn.Background = theme.Current().ContentSurfaceBg
+			return btn.Layout(gtx)
+		}),
+	)
+}
+
+// proxyList is the editor of the upstream proxies, in the order of the hops.
+type proxyList struct {
+	proxies []*proxy
+	btnAdd  widget.Clickable
+}
+
+func (l *proxyList) Set(proxies []config.Proxy) {
+	l.proxies = nil
+	for _, p := range proxies {
+		l.proxies = append(l.proxies, newProxy(p))
+	}
+}
+
+// Proxies returns the proxies in the list, the ones without a URL are ignored.
+func (l *proxyList) Proxies() ([]config.Proxy, error) {
+	var proxies []config.Proxy
+	for i, p := range l.proxies {
+		if strings.TrimSpace(p.url.Text()) == "" {
+			continue
+		}
+		v, err := p.Proxy()
+		if err != nil {
+			return nil, fmt.Errorf("proxy %d: %w", i+1, err)
+		}
+		proxies = append(proxies, v)
+	}
+	return proxies, nil
+}
+
+func (l *proxyList) Layout(gtx page.C, th *material.Theme) page.D {
+	if l.btnAdd.Clicked(gtx) {
+		l.proxies = append(l.proxies, newProxy(config.Proxy{}))
+	}
+	for i, p := range l.proxies {
+		if p.btnDelete.Clicked(gtx) {
+			l.proxies = slices.Delete(l.proxies, i, i+1)
+			break
+		}
+	}
+
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx page.C) page.D {
+			return layout.Inset{Top: 8, Bottom: 8}.Layout(gtx, func(gtx page.C) page.D {
+				return layout.Flex{
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Flexed(1, material.Body1(th, i18n.UpstreamProxies.Value()).Layout),
+					layout.Rigid(func(gtx page.C) page.D {
+						btn := material.IconButton(th, &l.btnAdd, icons.IconAdd, "Add")
+						btn.Color = th.Fg
+						btn.Background = theme.Current().ContentSurfaceBg
+						return btn.Layout(gtx)
+					}),
+				)
+			})
+		}),
+	}
+	for _, p := range l.proxies {
+		children = append(children, layout.Rigid(func(gtx page.C) page.D {
+			return p.Layout(gtx, th)
+		}))
+	}
+
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx, children...)
+}
@@ -0,0 +1,438 @@
+package share
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/op/paint"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/profile"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/entrypoint"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/icons"
+	"github.com/go-gost/gost.plus/ui/page"
+	"github.com/go-gost/gost.plus/ui/theme"
+	ui_widget "github.com/go-gost/gost.plus/ui/widget"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// qrSize is the size in pixels of the generated QR code image, it is scaled to fit the page.
+	qrSize = 512
+)
+
+type C = layout.Context
+type D = layout.Dimensions
+
+type item struct {
+	entrypoint bool
+	cfg        *config.Tunnel
+	sw         ui_widget.Switcher
+}
+
+type sharePage struct {
+	router *page.Router
+	menu   ui_widget.Menu
+	list   widget.List
+
+	btnBack widget.Clickable
+
+	items     []*item
+	strip     ui_widget.Switcher
+	format    ui_widget.Selector
+	btnExport widget.Clickable
+	btnCopy   widget.Clickable
+	output    component.TextField
+	qr        *widget.Image
+
+	input     component.TextField
+	keepIDs   ui_widget.Switcher
+	btnImport widget.Clickable
+}
+
+func NewPage(r *page.Router) page.Page {
+	return &sharePage{
+		router: r,
+		list: widget.List{
+			List: layout.List{
+				Axis: layout.Vertical,
+			},
+		},
+		format: ui_widget.Selector{Title: i18n.Format},
+		output: component.TextField{
+			Editor: widget.Editor{
+				ReadOnly: true,
+			},
+		},
+		input: component.TextField{
+			Editor: widget.Editor{},
+		},
+	}
+}
+
+func (p *sharePage) Init(opts ...page.PageOption) {
+	// make sure the config holds the latest changes of the running tunnels.
+	tunnel.SaveConfig()
+	entrypoint.SaveConfig()
+
+	cfg := config.Get()
+	p.items = nil
+	for _, c := range cfg.Tunnels {
+		if c != nil {
+			p.items = append(p.items, &item{cfg: c})
+		}
+	}
+	for _, c := range cfg.EntryPoints {
+		if c != nil {
+			p.items = append(p.items, &item{entrypoint: true, cfg: c})
+		}
+	}
+
+	p.strip.SetValue(true)
+	p.format.Clear()
+	p.format.Select(ui_widget.SelectorItem{Name: "YAML", Value: string(profile.FormatYAML)})
+	p.output.Clear()
+	p.qr = nil
+
+	p.input.Clear()
+	p.keepIDs.SetValue(false)
+}
+
+func (p *sharePage) Destroy() {
+	p.items = nil
+	p.qr = nil
+}
+
+func (p *sharePage) Layout(gtx C) D {
+	if p.btnBack.Clicked(gtx) {
+		p.router.Back()
+	}
+	if p.btnExport.Clicked(gtx) {
+		p.export()
+	}
+	if p.btnCopy.Clicked(gtx) && p.output.Text() != "" {
+		gtx.Execute(clipboard.WriteCmd{
+			Data: io.NopCloser(strings.NewReader(p.output.Text())),
+		})
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Success,
+			Content: i18n.Copied.Value(),
+		})
+	}
+	if p.btnImport.Clicked(gtx) {
+		p.importBundle()
+	}
+
+	th := p.router.Theme
+
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		// header
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{
+				Top:    8,
+				Bottom: 8,
+				Left:   8,
+				Right:  8,
+			}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Rigid(func(gtx C) D {
+						btn := material.IconButton(th, &p.btnBack, icons.IconBack, "Back")
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: 8}.Layout),
+					layout.Flexed(1, material.H6(th, i18n.Share.Value()).Layout),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx C) D {
+			return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+				return layout.Inset{
+					Top:    8,
+					Bottom: 8,
+					Left:   8,
+					Right:  8,
+				}.Layout(gtx, func(gtx C) D {
+					return layout.Flex{
+						Axis: layout.Vertical,
+					}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return p.layoutExport(gtx, th)
+						}),
+						layout.Rigid(layout.Spacer{Height: 16}.Layout),
+						layout.Rigid(func(gtx C) D {
+							return p.layoutImport(gtx, th)
+						}),
+					)
+				})
+			})
+		}),
+	)
+}
+
+func (p *sharePage) layoutExport(gtx C, th *material.Theme) D {
+	return surface(gtx, th, func(gtx C) D {
+		children := []layout.FlexChild{
+			layout.Rigid(func(gtx C) D {
+				return material.Body1(th, i18n.Export.Value()).Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Height: 4}.Layout),
+			layout.Rigid(material.Body2(th, i18n.ExportSelect.Value()).Layout),
+		}
+		for _, it := range p.items {
+			children = append(children, layout.Rigid(func(gtx C) D {
+				kind := i18n.Tunnel
+				if it.entrypoint {
+					kind = i18n.Entrypoint
+				}
+				it.sw.Title = fmt.Sprintf("%s (%s/%s)", it.cfg.Name, kind.Value(), it.cfg.Type)
+				return it.sw.Layout(gtx, th)
+			}))
+		}
+		children = append(children,
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+			layout.Rigid(func(gtx C) D {
+				p.strip.Title = i18n.StripSecrets.Value()
+				return p.strip.Layout(gtx, th)
+			}),
+			layout.Rigid(func(gtx C) D {
+				if p.format.Clicked(gtx) {
+					p.showFormatMenu(gtx)
+				}
+				return p.format.Layout(gtx, th)
+			}),
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+			layout.Rigid(func(gtx C) D {
+				return layout.Flex{
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Flexed(1, layout.Spacer{Width: 8}.Layout),
+					layout.Rigid(func(gtx C) D {
+						if p.output.Text() == "" {
+							return D{}
+						}
+						btn := material.IconButton(th, &p.btnCopy, icons.IconCopy, "Copy")
+						btn.Color = th.Fg
+						btn.Background = th.Bg
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: 8}.Layout),
+					layout.Rigid(func(gtx C) D {
+						return button(gtx, th, &p.btnExport, i18n.Export.Value())
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx C) D {
+				if p.output.Text() == "" {
+					return D{}
+				}
+				return p.output.Layout(gtx, th, "")
+			}),
+			layout.Rigid(func(gtx C) D {
+				if p.qr == nil {
+					return D{}
+				}
+				return layout.Inset{Top: 16}.Layout(gtx, func(gtx C) D {
+					return layout.Center.Layout(gtx, func(gtx C) D {
+						gtx.Constraints.Max.X = min(gtx.Constraints.Max.X, gtx.Dp(240))
+						gtx.Constraints.Min.X = gtx.Constraints.Max.X
+						return p.qr.Layout(gtx)
+					})
+				})
+			}),
+		)
+
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx, children...)
+	})
+}
+
+func (p *sharePage) layoutImport(gtx C, th *material.Theme) D {
+	return surface(gtx, th, func(gtx C) D {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			layout.Rigid(func(gtx C) D {
+				return material.Body1(th, i18n.Import.Value()).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx C) D {
+				return p.input.Layout(gtx, th, i18n.ImportHint.Value())
+			}),
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+			layout.Rigid(func(gtx C) D {
+				p.keepIDs.Title = i18n.KeepIDs.Value()
+				return p.keepIDs.Layout(gtx, th)
+			}),
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+			layout.Rigid(func(gtx C) D {
+				return layout.E.Layout(gtx, func(gtx C) D {
+					return button(gtx, th, &p.btnImport, i18n.Import.Value())
+				})
+			}),
+		)
+	})
+}
+
+func (p *sharePage) export() {
+	var tunnels, entrypoints []*config.Tunnel
+	for _, it := range p.items {
+		if !it.sw.Value() {
+			continue
+		}
+		if it.entrypoint {
+			entrypoints = append(entrypoints, it.cfg)
+		} else {
+			tunnels = append(tunnels, it.cfg)
+		}
+	}
+	if len(tunnels) == 0 && len(entrypoints) == 0 {
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Warn,
+			Content: i18n.ExportSelect.Value(),
+		})
+		return
+	}
+
+	format := profile.Format(p.format.Item().Value)
+	data, err := profile.Encode(profile.Export(tunnels, entrypoints, p.strip.Value()), format)
+	if err != nil {
+		p.notifyError(err)
+		return
+	}
+	p.output.SetText(string(data))
+
+	p.qr = nil
+	if format == profile.FormatURI {
+		qr, err := qrcode.New(string(data), qrcode.Low)
+		if err != nil {
+			p.notifyError(err)
+			return
+		}
+		p.qr = &widget.Image{
+			Src:      paint.NewImageOp(qr.Image(qrSize)),
+			Fit:      widget.Contain,
+			Position: layout.Center,
+		}
+	}
+}
+
+func (p *sharePage) importBundle() {
+	b, err := profile.Decode([]byte(p.input.Text()))
+	if err != nil {
+		p.notifyError(err)
+		return
+	}
+
+	policy := profile.RegenerateID
+	if p.keepIDs.Value() {
+		policy = profile.PreserveID
+	}
+
+	r, err := profile.Import(b, policy)
+	if err != nil {
+		p.notifyError(err)
+	}
+	if r.Created+r.Replaced+r.Skipped == 0 {
+		return
+	}
+
+	p.input.Clear()
+	p.router.Notify(ui_widget.Message{
+		Type:    ui_widget.Success,
+		Content: fmt.Sprintf(i18n.Imported.Value(), r.Created, r.Replaced, r.Skipped),
+	})
+	if r.Created+r.Replaced > 0 {
+		p.router.Notify(ui_widget.Message{
+			Type:    ui_widget.Warn,
+			Content: i18n.ReviewConfig.Value(),
+		})
+	}
+}
+
+func (p *sharePage) notifyError(err error) {
+	p.router.Notify(ui_widget.Message{
+		Type:    ui_widget.Error,
+		Content: err.Error(),
+	})
+}
+
+func (p *sharePage) showFormatMenu(gtx C) {
+	options := []ui_widget.MenuOption{
+		{Name: "YAML", Value: string(profile.FormatYAML)},
+		{Name: "JSON", Value: string(profile.FormatJSON)},
+		{Name: "URI / QR", Value: string(profile.FormatURI)},
+	}
+	for i := range options {
+		if p.format.AnyValue(options[i].Value) {
+			options[i].Selected = true
+		}
+	}
+
+	p.menu.Title = i18n.Format
+	p.menu.Options = options
+	p.menu.OnClick = func(ok bool) {
+		p.router.HideModal(gtx)
+		if !ok {
+			return
+		}
+
+		p.format.Clear()
+		for _, opt := range p.menu.Options {
+			if opt.Selected {
+				p.format.Select(ui_widget.SelectorItem{
+					Name:  opt.Name,
+					Value: opt.Value,
+				})
+				break
+			}
+		}
+	}
+
+	p.router.ShowModal(gtx, func(gtx page.C, th *material.Theme) page.D {
+		return p.menu.Layout(gtx, th)
+	})
+}
+
+func surface(gtx C, th *material.Theme, w layout.Widget) D {
+	return component.SurfaceStyle{
+		Theme: th,
+		ShadowStyle: component.ShadowStyle{
+			CornerRadius: 12,
+		},
+		Fill: theme.Current().ContentSurfaceBg,
+	}.Layout(gtx, func(gtx C) D {
+		return layout.UniformInset(16).Layout(gtx, w)
+	})
+}
+
+func button(gtx C, th *material.Theme, btn *widget.Clickable, text string) D {
+	return material.ButtonLayoutStyle{
+		Background:   theme.Current().ListBg,
+		CornerRadius: 18,
+		Button:       btn,
+	}.Layout(gtx, func(gtx C) D {
+		return layout.Inset{
+			Top:    8,
+			Bottom: 8,
+			Left:   20,
+			Right:  20,
+		}.Layout(gtx, func(gtx C) D {
+			label := material.Body1(th, text)
+			label.Color = th.Fg
+			return label.Layout(gtx)
+		})
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"gioui.org/x/component"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/history"
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/icons"
 	"github.com/go-gost/gost.plus/ui/page"
@@ -59,6 +60,8 @@ type filePage struct {
 	edit bool
 
 	delDialog ui_widget.Dialog
+
+	chart *ui_widget.TrafficChart
 }
 
 func NewPage(r *page.Router) page.Page {
@@ -91,6 +94,7 @@ func NewPage(r *page.Router) page.Page {
 		delDialog: ui_widget.Dialog{
 			Title: i18n.DeleteTunnel,
 		},
+		chart: ui_widget.NewTrafficChart(),
 	}
 }
 
@@ -267,13 +271,19 @@ func (p *filePage) Layout(gtx C) D {
 			})
 		}),
 		layout.Flexed(1, func(gtx C) D {
-			return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+			return p.list.Layout(gtx, 2, func(gtx C, index int) D {
 				return layout.Inset{
 					Top:    8,
 					Bottom: 8,
 					Left:   8,
 					Right:  8,
 				}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if index == 1 {
+						if p.id == "" {
+							return D{}
+						}
+						return p.chart.Layout(gtx, th, history.Tunnels.Lookup(p.id))
+					}
 					return p.layout(gtx, th)
 				})
 			})
@@ -512,7 +522,10 @@ func (p *filePage) create() error {
 func (p *filePage) update(opts ...tunnel.Option) tunnel.Tunnel {
 	defer tunnel.SaveConfig()
 
+	// the options which are not in the form are kept.
+	var prev tunnel.Options
 	if t := tunnel.Get(p.id); t != nil {
+		prev = t.Options()
 		t.Close()
 	}
 
@@ -528,6 +541,8 @@ func (p *filePage) update(opts ...tunnel.Option) tunnel.Tunnel {
 			tunnel.EndpointOption(strings.TrimSpace(p.endpoint.Text())),
 			tunnel.UsernameOption(username),
 			tunnel.PasswordOption(password),
+			tunnel.TransportOption(prev.Transport),
+			tunnel.ProxiesOption(prev.Proxies),
 		}
 	}
 	tun := tunnel.NewFileTunnel(opts...)
@@ -560,6 +575,8 @@ func (p *filePage) onoff() {
 			tunnel.EndpointOption(opts.Endpoint),
 			tunnel.UsernameOption(opts.Username),
 			tunnel.PasswordOption(opts.Password),
+			tunnel.TransportOption(opts.Transport),
+			tunnel.ProxiesOption(opts.Proxies),
 			tunnel.CreatedAtOption(opts.CreatedAt),
 		)
 	} else {
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"image/color"
+	"slices"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/icons"
+	"github.com/go-gost/gost.plus/ui/theme"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+)
+
+// headerRule is the editor of a rule setting or removing a header.
+type headerRule struct {
+	name      component.TextField
+	value     component.TextField
+	remove    widget.Bool
+	btnDelete widget.Clickable
+}
+
+func newHeaderRule(h config.HTTPHeader) *headerRule {
+	rule := &headerRule{
+		name:  component.TextField{Editor: widget.Editor{SingleLine: true}},
+		value: component.TextField{Editor: widget.Editor{SingleLine: true}},
+	}
+	rule.name.SetText(h.Name)
+	rule.value.SetText(h.Value)
+	rule.remove.Value = h.Remove
+	return rule
+}
+
+func (r *headerRule) Layout(gtx C, th *material.Theme) D {
+	return layout.Flex{
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return r.name.Layout(gtx, th, i18n.HeaderName.Value())
+		}),
+		layout.Rigid(layout.Spacer{Width: 8}.Layout),
+		layout.Flexed(1, func(gtx C) D {
+			if r.remove.Value {
+				r.value.Clear()
+				gtx = gtx.Disabled()
+			}
+			return r.value.Layout(gtx, th, i18n.HeaderValue.Value())
+		}),
+		layout.Rigid(layout.Spacer{Width: 8}.Layout),
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{
+				Axis:      layout.Vertical,
+				Alignment: layout.Middle,
+			}.Layout(gtx,
+				layout.Rigid(material.Caption(th, i18n.RemoveHeader.Value()).Layout),
+				layout.Rigid(material.Switch(th, &r.remove, "Remove").Layout),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			btn := material.IconButton(th, &r.btnDelete, icons.IconRemove, "Delete")
+			btn.Color = color.NRGBA(colornames.Red500)
+			btn.Background = theme.Current().ContentSurfaceBg
+			return btn.Layout(gtx)
+		}),
+	)
+}
+
+// headerList is the editor of the request or response header rules.
+type headerList struct {
+	Title  i18n.Key
+	rules  []*headerRule
+	btnAdd widget.Clickable
+}
+
+func (l *headerList) Set(headers []config.HTTPHeader) {
+	l.rules = nil
+	for _, h := range headers {
+		l.rules = append(l.rules, newHeaderRule(h))
+	}
+}
+
+// Headers returns the rules in the list, the ones without a name are ignored.
+func (l *headerList) Headers() []config.HTTPHeader {
+	var headers []config.HTTPHeader
+	for _, r := range l.rules {
+		h := config.HTTPHeader{
+			Name:   strings.TrimSpace(r.name.Text()),
+			Value:  strings.TrimSpace(r.value.Text()),
+			Remove: r.remove.Value,
+		}
+		if h.Name != "" {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+func (l *headerList) Layout(gtx C, th *material.Theme) D {
+	if l.btnAdd.Clicked(gtx) {
+		l.rules = append(l.rules, newHeaderRule(config.HTTPHeader{}))
+	}
+	for i, r := range l.rules {
+		if r.btnDelete.Clicked(gtx) {
+			l.rules = slices.Delete(l.rules, i, i+1)
+			break
+		}
+	}
+
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: 8, Bottom: 8}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Flexed(1, material.Body1(th, l.Title.Value()).Layout),
+					layout.Rigid(func(gtx C) D {
+						btn := material.IconButton(th, &l.btnAdd, icons.IconAdd, "Add")
+						btn.Color = th.Fg
+						btn.Background = theme.Current().ContentSurfaceBg
+						return btn.Layout(gtx)
+					}),
+				)
+			})
+		}),
+	}
+	for _, r := range l.rules {
+		children = append(children, layout.Rigid(func(gtx C) D {
+			return r.Layout(gtx, th)
+		}))
+	}
+
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx, children...)
+}
@@ -6,6 +6,7 @@ import (
 	"image/color"
 	"io"
 	"net"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,7 +17,9 @@ import (
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
 	"github.com/go-gost/core/logger"
+	"github.com/go-gost/gost.plus/config"
 	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/history"
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/icons"
 	"github.com/go-gost/gost.plus/ui/page"
@@ -47,6 +50,8 @@ type httpPage struct {
 
 	name     component.TextField
 	endpoint component.TextField
+	menu     ui_widget.Menu
+	strategy ui_widget.Selector
 
 	rewriteHost widget.Bool
 	hostname    component.TextField
@@ -56,6 +61,13 @@ type httpPage struct {
 	password  component.TextField
 
 	enableTLS widget.Bool
+	tls       *tlsSettings
+
+	routes      []*route
+	btnAddRoute widget.Clickable
+
+	reqHeaders headerList
+	resHeaders headerList
 
 	btnPasswordVisible widget.Clickable
 	passwordVisible    bool
@@ -65,6 +77,8 @@ type httpPage struct {
 
 	delDialog ui_widget.Dialog
 
+	chart *ui_widget.TrafficChart
+
 	btnInspector widget.Clickable
 }
 
@@ -100,9 +114,14 @@ func NewPage(r *page.Router) page.Page {
 				SingleLine: true,
 			},
 		},
+		strategy:   ui_widget.Selector{Title: i18n.Strategy},
+		reqHeaders: headerList{Title: i18n.RequestHeaders},
+		resHeaders: headerList{Title: i18n.ResponseHeaders},
 		delDialog: ui_widget.Dialog{
 			Title: i18n.DeleteTunnel,
 		},
+		tls:   newTLSSettings(),
+		chart: ui_widget.NewTrafficChart(),
 	}
 }
 
@@ -121,6 +140,8 @@ func (p *httpPage) Init(opts ...page.PageOption) {
 
 	p.name.Clear()
 	p.endpoint.Clear()
+	p.strategy.Clear()
+	p.strategy.Select(strategyItem(tunnel.StrategyRoundRobin))
 
 	p.rewriteHost.Value = false
 	p.hostname.Clear()
@@ -130,11 +151,25 @@ func (p *httpPage) Init(opts ...page.PageOption) {
 	p.password.Clear()
 	p.passwordVisible = false
 
+	p.enableTLS.Value = false
+	p.tls.Set(nil)
+
+	p.routes = nil
+	p.reqHeaders.Set(nil)
+	p.resHeaders.Set(nil)
+
 	s := tunnel.Get(p.id)
 	if s != nil {
 		sopts := s.Options()
 		p.name.SetText(sopts.Name)
 		p.endpoint.SetText(sopts.Endpoint)
+		for _, r := range sopts.Routes {
+			p.routes = append(p.routes, newRoute(r))
+		}
+		p.reqHeaders.Set(sopts.RequestHeaders)
+		p.resHeaders.Set(sopts.ResponseHeaders)
+		p.strategy.Clear()
+		p.strategy.Select(strategyItem(sopts.Strategy))
 		if sopts.Hostname != "" {
 			p.rewriteHost.Value = true
 			p.hostname.SetText(sopts.Hostname)
@@ -145,6 +180,7 @@ func (p *httpPage) Init(opts ...page.PageOption) {
 			p.password.SetText(sopts.Password)
 		}
 		p.enableTLS.Value = sopts.EnableTLS
+		p.tls.Set(sopts.TLS)
 	}
 }
 
@@ -288,13 +324,29 @@ func (p *httpPage) Layout(gtx C) D {
 			})
 		}),
 		layout.Flexed(1, func(gtx C) D {
-			return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+			return p.list.Layout(gtx, 4, func(gtx C, index int) D {
+				if index == 0 {
+					return p.layoutBackendErr(gtx, th)
+				}
 				return layout.Inset{
 					Top:    8,
 					Bottom: 8,
 					Left:   8,
 					Right:  8,
 				}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if index == 2 {
+						if p.id == "" {
+							return D{}
+						}
+						return p.chart.Layout(gtx, th, history.Tunnels.Lookup(p.id))
+					}
+					if index == 3 {
+						mb, _ := tunnel.Get(p.id).(tunnel.MultiBackend)
+						if mb == nil {
+							return D{}
+						}
+						return ui_widget.BackendList(gtx, th, mb.Backends())
+					}
 					return p.layout(gtx, th)
 				})
 			})
@@ -302,6 +354,27 @@ func (p *httpPage) Layout(gtx C) D {
 	)
 }
 
+// layoutBackendErr shows the failed TLS handshake with the endpoint, which does not stop the tunnel.
+func (p *httpPage) layoutBackendErr(gtx C, th *material.Theme) D {
+	be, _ := tunnel.Get(p.id).(tunnel.BackendErrorer)
+	if be == nil {
+		return D{}
+	}
+	err := be.BackendErr()
+	if err == nil {
+		return D{}
+	}
+	return layout.Inset{
+		Top:   8,
+		Left:  8,
+		Right: 8,
+	}.Layout(gtx, func(gtx C) D {
+		label := material.Body2(th, fmt.Sprintf(i18n.BackendTLSErr.Value(), err))
+		label.Color = color.NRGBA(colornames.Red600)
+		return label.Layout(gtx)
+	})
+}
+
 func (p *httpPage) layout(gtx C, th *material.Theme) D {
 	if p.btnInspector.Clicked(gtx) {
 		p.router.Goto(page.Route{
@@ -449,12 +522,10 @@ func (p *httpPage) layout(gtx C, th *material.Theme) D {
 				}),
 				layout.Rigid(func(gtx C) D {
 					if err := func() error {
-						addr := strings.TrimSpace(p.endpoint.Text())
-						if addr == "" {
-							return nil
-						}
-						if _, err := net.ResolveTCPAddr("tcp", addr); err != nil {
-							return fmt.Errorf(i18n.ErrInvalidAddr.Value())
+						for _, addr := range tunnel.Endpoints(p.endpoint.Text()) {
+							if _, err := net.ResolveTCPAddr("tcp", addr); err != nil {
+								return fmt.Errorf(i18n.ErrInvalidAddr.Value())
+							}
 						}
 						return nil
 					}(); err != nil {
@@ -463,7 +534,21 @@ func (p *httpPage) layout(gtx C, th *material.Theme) D {
 						p.endpoint.ClearError()
 					}
 
-					return p.endpoint.Layout(gtx, th, i18n.Address.Value())
+					return p.endpoint.Layout(gtx, th, i18n.EndpointHint.Value())
+				}),
+				layout.Rigid(func(gtx C) D {
+					if len(tunnel.Endpoints(p.endpoint.Text())) <= 1 {
+						return D{}
+					}
+
+					if p.strategy.Clicked(gtx) {
+						p.showStrategyMenu(gtx)
+					}
+					return layout.Inset{
+						Top: 8,
+					}.Layout(gtx, func(gtx C) D {
+						return p.strategy.Layout(gtx, th)
+					})
 				}),
 				layout.Rigid(layout.Spacer{Height: 8}.Layout),
 
@@ -562,6 +647,73 @@ func (p *httpPage) layout(gtx C, th *material.Theme) D {
 						)
 					})
 				}),
+				layout.Rigid(func(gtx C) D {
+					if !p.enableTLS.Value {
+						return layout.Dimensions{}
+					}
+					return p.tls.Layout(gtx, th, p.router)
+				}),
+
+				layout.Rigid(func(gtx C) D {
+					return p.reqHeaders.Layout(gtx, th)
+				}),
+				layout.Rigid(func(gtx C) D {
+					return p.resHeaders.Layout(gtx, th)
+				}),
+
+				layout.Rigid(func(gtx C) D {
+					if p.btnAddRoute.Clicked(gtx) {
+						p.routes = append(p.routes, newRoute(config.HTTPRoute{}))
+					}
+
+					return layout.Inset{Top: 8, Bottom: 8}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{
+							Alignment: layout.Middle,
+						}.Layout(gtx,
+							layout.Flexed(1, func(gtx C) D {
+								return layout.Flex{
+									Axis: layout.Vertical,
+								}.Layout(gtx,
+									layout.Rigid(material.Body1(th, i18n.Routes.Value()).Layout),
+									layout.Rigid(func(gtx C) D {
+										label := material.Caption(th, i18n.RoutesDesc.Value())
+										label.Color = color.NRGBA(colornames.Grey500)
+										return label.Layout(gtx)
+									}),
+								)
+							}),
+							layout.Rigid(layout.Spacer{Width: 8}.Layout),
+							layout.Rigid(func(gtx C) D {
+								btn := material.IconButton(th, &p.btnAddRoute, icons.IconAdd, "Add")
+								btn.Color = th.Fg
+								btn.Background = theme.Current().ContentSurfaceBg
+								return btn.Layout(gtx)
+							}),
+						)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					for i, r := range p.routes {
+						if r.btnRemove.Clicked(gtx) {
+							p.routes = slices.Delete(p.routes, i, i+1)
+							break
+						}
+					}
+
+					var children []layout.FlexChild
+					for _, r := range p.routes {
+						children = append(children,
+							layout.Rigid(func(gtx C) D {
+								return layout.Inset{Bottom: 8}.Layout(gtx, func(gtx C) D {
+									return r.Layout(gtx, th)
+								})
+							}),
+						)
+					}
+					return layout.Flex{
+						Axis: layout.Vertical,
+					}.Layout(gtx, children...)
+				}),
 				layout.Rigid(layout.Spacer{Height: 8}.Layout),
 			)
 		})
@@ -583,10 +735,15 @@ func (p *httpPage) create() error {
 	tun := tunnel.NewHTTPTunnel(
 		tunnel.NameOption(strings.TrimSpace(p.name.Text())),
 		tunnel.EndpointOption(strings.TrimSpace(p.endpoint.Text())),
+		tunnel.StrategyOption(p.strategy.Item().Value),
 		tunnel.UsernameOption(username),
 		tunnel.PasswordOption(password),
 		tunnel.HostnameOption(hostname),
 		tunnel.EnableTLSOption(p.enableTLS.Value),
+		tunnel.TLSOption(p.tls.TLS()),
+		tunnel.RoutesOption(p.routeList()),
+		tunnel.RequestHeadersOption(p.reqHeaders.Headers()),
+		tunnel.ResponseHeadersOption(p.resHeaders.Headers()),
 	)
 
 	tunnel.Add(tun)
@@ -606,7 +763,10 @@ func (p *httpPage) create() error {
 func (p *httpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 	defer tunnel.SaveConfig()
 
+	// the options which are not in the form are kept.
+	var prev tunnel.Options
 	if t := tunnel.Get(p.id); t != nil {
+		prev = t.Options()
 		t.Close()
 	}
 
@@ -624,10 +784,17 @@ func (p *httpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 			tunnel.NameOption(strings.TrimSpace(p.name.Text())),
 			tunnel.IDOption(p.id),
 			tunnel.EndpointOption(strings.TrimSpace(p.endpoint.Text())),
+			tunnel.StrategyOption(p.strategy.Item().Value),
 			tunnel.UsernameOption(username),
 			tunnel.PasswordOption(password),
 			tunnel.HostnameOption(hostname),
 			tunnel.EnableTLSOption(p.enableTLS.Value),
+			tunnel.TLSOption(p.tls.TLS()),
+			tunnel.RoutesOption(p.routeList()),
+			tunnel.RequestHeadersOption(p.reqHeaders.Headers()),
+			tunnel.ResponseHeadersOption(p.resHeaders.Headers()),
+			tunnel.TransportOption(prev.Transport),
+			tunnel.ProxiesOption(prev.Proxies),
 		}
 	}
 	tun := tunnel.NewHTTPTunnel(opts...)
@@ -658,10 +825,17 @@ func (p *httpPage) onoff() {
 			tunnel.NameOption(opts.Name),
 			tunnel.IDOption(opts.ID),
 			tunnel.EndpointOption(opts.Endpoint),
+			tunnel.StrategyOption(opts.Strategy),
 			tunnel.UsernameOption(opts.Username),
 			tunnel.PasswordOption(opts.Password),
 			tunnel.HostnameOption(opts.Hostname),
 			tunnel.EnableTLSOption(opts.EnableTLS),
+			tunnel.TLSOption(opts.TLS),
+			tunnel.RoutesOption(opts.Routes),
+			tunnel.RequestHeadersOption(opts.RequestHeaders),
+			tunnel.ResponseHeadersOption(opts.ResponseHeaders),
+			tunnel.TransportOption(opts.Transport),
+			tunnel.ProxiesOption(opts.Proxies),
 			tunnel.CreatedAtOption(opts.CreatedAt),
 		)
 	} else {
@@ -670,7 +844,63 @@ func (p *httpPage) onoff() {
 	tunnel.SaveConfig()
 }
 
+// routeList returns the routes in the form, the ones without an endpoint are ignored.
+func (p *httpPage) routeList() []config.HTTPRoute {
+	var routes []config.HTTPRoute
+	for _, r := range p.routes {
+		if route := r.Route(); route.Endpoint != "" {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
 func (p *httpPage) delete() {
 	tunnel.Delete(p.id)
 	tunnel.SaveConfig()
 }
+
+func strategyItem(strategy string) ui_widget.SelectorItem {
+	switch strategy {
+	case tunnel.StrategyRandom:
+		return ui_widget.SelectorItem{Key: i18n.Random, Value: tunnel.StrategyRandom}
+	case tunnel.StrategyFailover:
+		return ui_widget.SelectorItem{Key: i18n.Failover, Value: tunnel.StrategyFailover}
+	default:
+		return ui_widget.SelectorItem{Key: i18n.RoundRobin, Value: tunnel.StrategyRoundRobin}
+	}
+}
+
+func (p *httpPage) showStrategyMenu(gtx C) {
+	options := []ui_widget.MenuOption{
+		{Key: i18n.RoundRobin, Value: tunnel.StrategyRoundRobin},
+		{Key: i18n.Random, Value: tunnel.StrategyRandom},
+		{Key: i18n.Failover, Value: tunnel.StrategyFailover},
+	}
+	for i := range options {
+		if p.strategy.AnyValue(options[i].Value) {
+			options[i].Selected = true
+		}
+	}
+
+	p.menu.Title = i18n.Strategy
+	p.menu.Options = options
+	p.menu.OnClick = func(ok bool) {
+		p.router.HideModal(gtx)
+		if !ok {
+			return
+		}
+
+		p.strategy.Clear()
+		for _, opt := range p.menu.Options {
+			if opt.Selected {
+				p.strategy.Select(strategyItem(opt.Value))
+				break
+			}
+		}
+	}
+
+	p.router.ShowModal(gtx, func(gtx page.C, th *material.Theme) page.D {
+		return p.menu.Layout(gtx, th)
+	})
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"fmt"
+	"image/color"
+	"net"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/icons"
+	"github.com/go-gost/gost.plus/ui/theme"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+)
+
+// route is the editor of a routing rule of the HTTP tunnel.
+type route struct {
+	path        component.TextField
+	host        component.TextField
+	header      component.TextField
+	endpoint    component.TextField
+	stripPrefix widget.Bool
+	btnRemove   widget.Clickable
+}
+
+func newRoute(r config.HTTPRoute) *route {
+	rt := &route{
+		path:     component.TextField{Editor: widget.Editor{SingleLine: true}},
+		host:     component.TextField{Editor: widget.Editor{SingleLine: true}},
+		header:   component.TextField{Editor: widget.Editor{SingleLine: true}},
+		endpoint: component.TextField{Editor: widget.Editor{SingleLine: true}},
+	}
+	rt.path.SetText(r.Path)
+	rt.host.SetText(r.Host)
+	rt.header.SetText(r.Header)
+	rt.endpoint.SetText(r.Endpoint)
+	rt.stripPrefix.Value = r.StripPrefix
+	return rt
+}
+
+func (r *route) Route() config.HTTPRoute {
+	return config.HTTPRoute{
+		Host:        strings.TrimSpace(r.host.Text()),
+		Path:        strings.TrimSpace(r.path.Text()),
+		Header:      strings.TrimSpace(r.header.Text()),
+		StripPrefix: r.stripPrefix.Value,
+		Endpoint:    strings.TrimSpace(r.endpoint.Text()),
+	}
+}
+
+func (r *route) Layout(gtx C, th *material.Theme) D {
+	return component.SurfaceStyle{
+		Theme: th,
+		ShadowStyle: component.ShadowStyle{
+			CornerRadius: 12,
+		},
+		Fill: theme.Current().ItemBg,
+	}.Layout(gtx, func(gtx C) D {
+		return layout.Inset{
+			Top:    4,
+			Bottom: 8,
+			Left:   12,
+			Right:  4,
+		}.Layout(gtx, func(gtx C) D {
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.Flex{
+						Alignment: layout.Middle,
+					}.Layout(gtx,
+						layout.Flexed(1, func(gtx C) D {
+							path := r.path.Text()
+							if path != "" && !strings.HasPrefix(path, "/") {
+								r.path.SetError(i18n.ErrInvalidPath.Value())
+							} else {
+								r.path.ClearError()
+							}
+							return r.path.Layout(gtx, th, i18n.PathPrefix.Value())
+						}),
+						layout.Rigid(func(gtx C) D {
+							btn := material.IconButton(th, &r.btnRemove, icons.IconRemove, "Remove")
+							btn.Color = color.NRGBA(colornames.Red500)
+							btn.Background = theme.Current().ItemBg
+							return btn.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Right: 8}.Layout(gtx, func(gtx C) D {
+						return layout.Flex{
+							Spacing: layout.SpaceBetween,
+						}.Layout(gtx,
+							layout.Flexed(1, material.Body2(th, i18n.StripPrefix.Value()).Layout),
+							layout.Rigid(material.Switch(th, &r.stripPrefix, "Strip prefix").Layout),
+						)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Right: 8}.Layout(gtx, func(gtx C) D {
+						return r.host.Layout(gtx, th, i18n.Hostname.Value())
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.Inset{Right: 8}.Layout(gtx, func(gtx C) D {
+						return r.header.Layout(gtx, th, i18n.HeaderHint.Value())
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					if err := func() error {
+						addr := strings.TrimSpace(r.endpoint.Text())
+						if addr == "" {
+							return nil
+						}
+						if _, err := net.ResolveTCPAddr("tcp", addr); err != nil {
+							return fmt.Errorf(i18n.ErrInvalidAddr.Value())
+						}
+						return nil
+					}(); err != nil {
+						r.endpoint.SetError(err.Error())
+					} else {
+						r.endpoint.ClearError()
+					}
+
+					return layout.Inset{Right: 8}.Layout(gtx, func(gtx C) D {
+						return r.endpoint.Layout(gtx, th, i18n.Endpoint.Value())
+					})
+				}),
+			)
+		})
+	})
+}
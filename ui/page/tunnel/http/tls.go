@@ -0,0 +1,95 @@
+package http
+
+import (
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/config"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/page"
+)
+
+// tlsSettings is the editor of the TLS to the endpoints.
+type tlsSettings struct {
+	ca         *page.FileField
+	serverName component.TextField
+	cert       *page.FileField
+	key        *page.FileField
+	insecure   widget.Bool
+}
+
+func newTLSSettings() *tlsSettings {
+	return &tlsSettings{
+		ca:         page.NewFileField(),
+		serverName: component.TextField{Editor: widget.Editor{SingleLine: true}},
+		cert:       page.NewFileField(),
+		key:        page.NewFileField(),
+	}
+}
+
+func (s *tlsSettings) Set(t *config.TLS) {
+	if t == nil {
+		t = &config.TLS{}
+	}
+	s.ca.SetText(t.CA)
+	s.serverName.SetText(t.ServerName)
+	s.cert.SetText(t.Cert)
+	s.key.SetText(t.Key)
+	s.insecure.Value = t.Insecure
+}
+
+// TLS returns the settings, it is nil if nothing is set.
+func (s *tlsSettings) TLS() *config.TLS {
+	t := &config.TLS{
+		CA:         strings.TrimSpace(s.ca.Text()),
+		ServerName: strings.TrimSpace(s.serverName.Text()),
+		Cert:       strings.TrimSpace(s.cert.Text()),
+		Key:        strings.TrimSpace(s.key.Text()),
+		Insecure:   s.insecure.Value,
+	}
+	if *t == (config.TLS{}) {
+		return nil
+	}
+	return t
+}
+
+func (s *tlsSettings) Layout(gtx C, th *material.Theme, router *page.Router) D {
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx C) D {
+			return layout.Inset{Top: 8, Bottom: 8}.Layout(gtx, func(gtx C) D {
+				return layout.Flex{
+					Spacing: layout.SpaceBetween,
+				}.Layout(gtx,
+					layout.Flexed(1, material.Body1(th, i18n.TLSInsecure.Value()).Layout),
+					layout.Rigid(material.Switch(th, &s.insecure, "Skip verification").Layout),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx C) D {
+			if s.insecure.Value {
+				gtx = gtx.Disabled()
+			}
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return s.ca.Layout(gtx, th, router, i18n.TLSCA.Value())
+				}),
+				layout.Rigid(func(gtx C) D {
+					return s.serverName.Layout(gtx, th, i18n.TLSServerName.Value())
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx C) D {
+			return s.cert.Layout(gtx, th, router, i18n.TLSCert.Value())
+		}),
+		layout.Rigid(func(gtx C) D {
+			return s.key.Layout(gtx, th, router, i18n.TLSKey.Value())
+		}),
+	)
+}
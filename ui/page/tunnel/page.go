@@ -61,6 +61,16 @@ func NewPage(r *page.Router) page.Page {
 				desc: i18n.UDPTunnelDesc,
 				path: page.PageTunnelUDP,
 			},
+			{
+				name: "Proxy",
+				desc: i18n.ProxyTunnelDesc,
+				path: page.PageTunnelProxy,
+			},
+			{
+				name: "Advanced",
+				desc: i18n.AdvancedTunnelDesc,
+				path: page.PageTunnelAdvanced,
+			},
 		},
 	}
 }
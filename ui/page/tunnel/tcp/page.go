@@ -17,6 +17,7 @@ import (
 	"gioui.org/x/component"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/history"
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/icons"
 	"github.com/go-gost/gost.plus/ui/page"
@@ -47,11 +48,15 @@ type tcpPage struct {
 
 	name     component.TextField
 	endpoint component.TextField
+	menu     ui_widget.Menu
+	strategy ui_widget.Selector
 
 	id   string
 	edit bool
 
 	delDialog ui_widget.Dialog
+
+	chart *ui_widget.TrafficChart
 }
 
 func NewPage(r *page.Router) page.Page {
@@ -71,9 +76,11 @@ func NewPage(r *page.Router) page.Page {
 				SingleLine: true,
 			},
 		},
+		strategy: ui_widget.Selector{Title: i18n.Strategy},
 		delDialog: ui_widget.Dialog{
 			Title: i18n.DeleteTunnel,
 		},
+		chart: ui_widget.NewTrafficChart(),
 	}
 }
 
@@ -92,12 +99,16 @@ func (p *tcpPage) Init(opts ...page.PageOption) {
 
 	p.name.Clear()
 	p.endpoint.Clear()
+	p.strategy.Clear()
+	p.strategy.Select(strategyItem(tunnel.StrategyRoundRobin))
 
 	s := tunnel.Get(p.id)
 	if s != nil {
 		sopts := s.Options()
 		p.name.SetText(sopts.Name)
 		p.endpoint.SetText(sopts.Endpoint)
+		p.strategy.Clear()
+		p.strategy.Select(strategyItem(sopts.Strategy))
 	}
 }
 
@@ -241,13 +252,26 @@ func (p *tcpPage) Layout(gtx C) D {
 			})
 		}),
 		layout.Flexed(1, func(gtx C) D {
-			return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+			return p.list.Layout(gtx, 3, func(gtx C, index int) D {
 				return layout.Inset{
 					Top:    8,
 					Bottom: 8,
 					Left:   8,
 					Right:  8,
 				}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if index == 1 {
+						if p.id == "" {
+							return D{}
+						}
+						return p.chart.Layout(gtx, th, history.Tunnels.Lookup(p.id))
+					}
+					if index == 2 {
+						mb, _ := tunnel.Get(p.id).(tunnel.MultiBackend)
+						if mb == nil {
+							return D{}
+						}
+						return ui_widget.BackendList(gtx, th, mb.Backends())
+					}
 					return p.layout(gtx, th)
 				})
 			})
@@ -368,12 +392,10 @@ func (p *tcpPage) layout(gtx C, th *material.Theme) D {
 				}),
 				layout.Rigid(func(gtx C) D {
 					if err := func() error {
-						addr := strings.TrimSpace(p.endpoint.Text())
-						if addr == "" {
-							return nil
-						}
-						if _, err := net.ResolveTCPAddr("tcp", addr); err != nil {
-							return fmt.Errorf(i18n.ErrInvalidAddr.Value())
+						for _, addr := range tunnel.Endpoints(p.endpoint.Text()) {
+							if _, err := net.ResolveTCPAddr("tcp", addr); err != nil {
+								return fmt.Errorf(i18n.ErrInvalidAddr.Value())
+							}
 						}
 						return nil
 					}(); err != nil {
@@ -382,7 +404,21 @@ func (p *tcpPage) layout(gtx C, th *material.Theme) D {
 						p.endpoint.ClearError()
 					}
 
-					return p.endpoint.Layout(gtx, th, i18n.Address.Value())
+					return p.endpoint.Layout(gtx, th, i18n.EndpointHint.Value())
+				}),
+				layout.Rigid(func(gtx C) D {
+					if len(tunnel.Endpoints(p.endpoint.Text())) <= 1 {
+						return D{}
+					}
+
+					if p.strategy.Clicked(gtx) {
+						p.showStrategyMenu(gtx)
+					}
+					return layout.Inset{
+						Top: 8,
+					}.Layout(gtx, func(gtx C) D {
+						return p.strategy.Layout(gtx, th)
+					})
 				}),
 				layout.Rigid(layout.Spacer{Height: 8}.Layout),
 			)
@@ -396,6 +432,7 @@ func (p *tcpPage) create() error {
 	tun := tunnel.NewTCPTunnel(
 		tunnel.NameOption(strings.TrimSpace(p.name.Text())),
 		tunnel.EndpointOption(strings.TrimSpace(p.endpoint.Text())),
+		tunnel.StrategyOption(p.strategy.Item().Value),
 	)
 
 	tunnel.Add(tun)
@@ -415,7 +452,10 @@ func (p *tcpPage) create() error {
 func (p *tcpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 	defer tunnel.SaveConfig()
 
+	// the options which are not in the form are kept.
+	var prev tunnel.Options
 	if t := tunnel.Get(p.id); t != nil {
+		prev = t.Options()
 		t.Close()
 	}
 
@@ -424,6 +464,9 @@ func (p *tcpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 			tunnel.NameOption(strings.TrimSpace(p.name.Text())),
 			tunnel.IDOption(p.id),
 			tunnel.EndpointOption(strings.TrimSpace(p.endpoint.Text())),
+			tunnel.StrategyOption(p.strategy.Item().Value),
+			tunnel.TransportOption(prev.Transport),
+			tunnel.ProxiesOption(prev.Proxies),
 		}
 	}
 	tun := tunnel.NewTCPTunnel(opts...)
@@ -454,6 +497,9 @@ func (p *tcpPage) onoff() {
 			tunnel.NameOption(opts.Name),
 			tunnel.IDOption(opts.ID),
 			tunnel.EndpointOption(opts.Endpoint),
+			tunnel.StrategyOption(opts.Strategy),
+			tunnel.TransportOption(opts.Transport),
+			tunnel.ProxiesOption(opts.Proxies),
 			tunnel.CreatedAtOption(opts.CreatedAt),
 		)
 	} else {
@@ -466,3 +512,48 @@ func (p *tcpPage) delete() {
 	tunnel.Delete(p.id)
 	tunnel.SaveConfig()
 }
+
+func strategyItem(strategy string) ui_widget.SelectorItem {
+	switch strategy {
+	case tunnel.StrategyRandom:
+		return ui_widget.SelectorItem{Key: i18n.Random, Value: tunnel.StrategyRandom}
+	case tunnel.StrategyFailover:
+		return ui_widget.SelectorItem{Key: i18n.Failover, Value: tunnel.StrategyFailover}
+	default:
+		return ui_widget.SelectorItem{Key: i18n.RoundRobin, Value: tunnel.StrategyRoundRobin}
+	}
+}
+
+func (p *tcpPage) showStrategyMenu(gtx C) {
+	options := []ui_widget.MenuOption{
+		{Key: i18n.RoundRobin, Value: tunnel.StrategyRoundRobin},
+		{Key: i18n.Random, Value: tunnel.StrategyRandom},
+		{Key: i18n.Failover, Value: tunnel.StrategyFailover},
+	}
+	for i := range options {
+		if p.strategy.AnyValue(options[i].Value) {
+			options[i].Selected = true
+		}
+	}
+
+	p.menu.Title = i18n.Strategy
+	p.menu.Options = options
+	p.menu.OnClick = func(ok bool) {
+		p.router.HideModal(gtx)
+		if !ok {
+			return
+		}
+
+		p.strategy.Clear()
+		for _, opt := range p.menu.Options {
+			if opt.Selected {
+				p.strategy.Select(strategyItem(opt.Value))
+				break
+			}
+		}
+	}
+
+	p.router.ShowModal(gtx, func(gtx page.C, th *material.Theme) page.D {
+		return p.menu.Layout(gtx, th)
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"gioui.org/x/component"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/tunnel/history"
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/icons"
 	"github.com/go-gost/gost.plus/ui/page"
@@ -52,6 +53,8 @@ type udpPage struct {
 	edit bool
 
 	delDialog ui_widget.Dialog
+
+	chart *ui_widget.TrafficChart
 }
 
 func NewPage(r *page.Router) page.Page {
@@ -74,6 +77,7 @@ func NewPage(r *page.Router) page.Page {
 		delDialog: ui_widget.Dialog{
 			Title: i18n.DeleteTunnel,
 		},
+		chart: ui_widget.NewTrafficChart(),
 	}
 }
 
@@ -241,13 +245,19 @@ func (p *udpPage) Layout(gtx C) D {
 			})
 		}),
 		layout.Flexed(1, func(gtx C) D {
-			return p.list.Layout(gtx, 1, func(gtx C, _ int) D {
+			return p.list.Layout(gtx, 2, func(gtx C, index int) D {
 				return layout.Inset{
 					Top:    8,
 					Bottom: 8,
 					Left:   8,
 					Right:  8,
 				}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if index == 1 {
+						if p.id == "" {
+							return D{}
+						}
+						return p.chart.Layout(gtx, th, history.Tunnels.Lookup(p.id))
+					}
 					return p.layout(gtx, th)
 				})
 			})
@@ -415,7 +425,10 @@ func (p *udpPage) create() error {
 func (p *udpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 	defer tunnel.SaveConfig()
 
+	// the options which are not in the form are kept.
+	var prev tunnel.Options
 	if t := tunnel.Get(p.id); t != nil {
+		prev = t.Options()
 		t.Close()
 	}
 
@@ -424,6 +437,8 @@ func (p *udpPage) update(opts ...tunnel.Option) tunnel.Tunnel {
 			tunnel.NameOption(strings.TrimSpace(p.name.Text())),
 			tunnel.IDOption(p.id),
 			tunnel.EndpointOption(strings.TrimSpace(p.endpoint.Text())),
+			tunnel.TransportOption(prev.Transport),
+			tunnel.ProxiesOption(prev.Proxies),
 		}
 	}
 	tun := tunnel.NewUDPTunnel(opts...)
@@ -454,6 +469,8 @@ func (p *udpPage) onoff() {
 			tunnel.NameOption(opts.Name),
 			tunnel.IDOption(opts.ID),
 			tunnel.EndpointOption(opts.Endpoint),
+			tunnel.TransportOption(opts.Transport),
+			tunnel.ProxiesOption(opts.Proxies),
 			tunnel.CreatedAtOption(opts.CreatedAt),
 		)
 	} else {
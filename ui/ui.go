@@ -11,13 +11,20 @@ import (
 	"github.com/go-gost/gost.plus/ui/i18n"
 	"github.com/go-gost/gost.plus/ui/page"
 	"github.com/go-gost/gost.plus/ui/page/entrypoint"
+	file_ep "github.com/go-gost/gost.plus/ui/page/entrypoint/file"
+	http_ep "github.com/go-gost/gost.plus/ui/page/entrypoint/http"
 	tcp_ep "github.com/go-gost/gost.plus/ui/page/entrypoint/tcp"
 	udp_ep "github.com/go-gost/gost.plus/ui/page/entrypoint/udp"
 	"github.com/go-gost/gost.plus/ui/page/home"
+	"github.com/go-gost/gost.plus/ui/page/inspector"
+	"github.com/go-gost/gost.plus/ui/page/server"
 	"github.com/go-gost/gost.plus/ui/page/settings"
+	"github.com/go-gost/gost.plus/ui/page/share"
 	"github.com/go-gost/gost.plus/ui/page/tunnel"
+	"github.com/go-gost/gost.plus/ui/page/tunnel/advanced"
 	"github.com/go-gost/gost.plus/ui/page/tunnel/file"
 	"github.com/go-gost/gost.plus/ui/page/tunnel/http"
+	"github.com/go-gost/gost.plus/ui/page/tunnel/proxy"
 	"github.com/go-gost/gost.plus/ui/page/tunnel/tcp"
 	"github.com/go-gost/gost.plus/ui/page/tunnel/udp"
 	"github.com/go-gost/gost.plus/ui/theme"
@@ -61,10 +68,17 @@ func NewUI() *UI {
 	router.Register(page.PageTunnelHTTP, http.NewPage(router))
 	router.Register(page.PageTunnelTCP, tcp.NewPage(router))
 	router.Register(page.PageTunnelUDP, udp.NewPage(router))
+	router.Register(page.PageTunnelProxy, proxy.NewPage(router))
+	router.Register(page.PageTunnelAdvanced, advanced.NewPage(router))
 	router.Register(page.PageEntrypoint, entrypoint.NewPage(router))
 	router.Register(page.PageEntrypointTCP, tcp_ep.NewPage(router))
 	router.Register(page.PageEntrypointUDP, udp_ep.NewPage(router))
+	router.Register(page.PageEntrypointHTTP, http_ep.NewPage(router))
+	router.Register(page.PageEntrypointFile, file_ep.NewPage(router))
 	router.Register(page.PageSettings, settings.NewPage(router))
+	router.Register(page.PageServer, server.NewPage(router))
+	router.Register(page.PageInspector, inspector.NewPage(router))
+	router.Register(page.PageShare, share.NewPage(router))
 
 	router.Goto(page.Route{
 		Path: page.PageHome,
@@ -0,0 +1,87 @@
+package widget
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/tunnel"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/theme"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+)
+
+// BackendList lists the stats of the backends of a tunnel with multiple endpoints, nothing is drawn for a single backend.
+func BackendList(gtx layout.Context, th *material.Theme, backends []tunnel.BackendStats) layout.Dimensions {
+	if len(backends) <= 1 {
+		return layout.Dimensions{}
+	}
+
+	return component.SurfaceStyle{
+		Theme: th,
+		ShadowStyle: component.ShadowStyle{
+			CornerRadius: 12,
+		},
+		Fill: theme.Current().ContentSurfaceBg,
+	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.UniformInset(16).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			children := []layout.FlexChild{
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := material.Body1(th, i18n.Backends.Value())
+					label.Font.Weight = font.SemiBold
+					return label.Layout(gtx)
+				}),
+			}
+			for _, b := range backends {
+				children = append(children,
+					layout.Rigid(layout.Spacer{Height: 8}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layoutBackend(gtx, th, b)
+					}),
+				)
+			}
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx, children...)
+		})
+	})
+}
+
+func layoutBackend(gtx layout.Context, th *material.Theme, b tunnel.BackendStats) layout.Dimensions {
+	c := color.NRGBA(colornames.Green500)
+	if b.Down {
+		c = color.NRGBA(colornames.Red500)
+	}
+
+	return layout.Flex{
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			size := gtx.Dp(8)
+			defer clip.Ellipse{Max: image.Pt(size, size)}.Push(gtx.Ops).Pop()
+			paint.ColorOp{Color: c}.Add(gtx.Ops)
+			paint.PaintOp{}.Add(gtx.Ops)
+			return layout.Dimensions{Size: image.Pt(size, size)}
+		}),
+		layout.Rigid(layout.Spacer{Width: 8}.Layout),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx,
+				layout.Rigid(material.Body2(th, b.Addr).Layout),
+				layout.Rigid(material.Caption(th, fmt.Sprintf("%s %d/%d  %s %d  %s %s / %s %s",
+					i18n.Connections.Value(), b.CurrentConns, b.TotalConns,
+					i18n.Errors.Value(), b.TotalErrs,
+					i18n.Inbound.Value(), formatBytes(b.InputBytes),
+					i18n.Outbound.Value(), formatBytes(b.OutputBytes),
+				)).Layout),
+			)
+		}),
+	)
+}
@@ -0,0 +1,237 @@
+package widget
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/go-gost/gost.plus/tunnel/history"
+	"github.com/go-gost/gost.plus/ui/i18n"
+	"github.com/go-gost/gost.plus/ui/theme"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+)
+
+type chartRange struct {
+	name     string
+	duration time.Duration
+	btn      widget.Clickable
+}
+
+// TrafficChart draws the input and output rates of a service history.
+type TrafficChart struct {
+	ranges   []chartRange
+	selected int
+}
+
+func NewTrafficChart() *TrafficChart {
+	return &TrafficChart{
+		ranges: []chartRange{
+			{name: "1H", duration: time.Hour},
+			{name: "1D", duration: 24 * time.Hour},
+			{name: "30D", duration: 30 * 24 * time.Hour},
+		},
+	}
+}
+
+var (
+	chartInputColor  = color.NRGBA(colornames.Blue500)
+	chartOutputColor = color.NRGBA(colornames.Green500)
+)
+
+func (p *TrafficChart) Layout(gtx layout.Context, th *material.Theme, h *history.History) layout.Dimensions {
+	if h == nil {
+		return layout.Dimensions{}
+	}
+
+	for i := range p.ranges {
+		if p.ranges[i].btn.Clicked(gtx) {
+			p.selected = i
+		}
+	}
+
+	d := p.ranges[p.selected].duration
+	samples, resolution := h.Samples(d)
+
+	var max uint64
+	for _, s := range samples {
+		if s.InputRateBytes > max {
+			max = s.InputRateBytes
+		}
+		if s.OutputRateBytes > max {
+			max = s.OutputRateBytes
+		}
+	}
+
+	return component.SurfaceStyle{
+		Theme: th,
+		ShadowStyle: component.ShadowStyle{
+			CornerRadius: 12,
+		},
+		Fill: theme.Current().ContentSurfaceBg,
+	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.UniformInset(16).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Alignment: layout.Middle,
+					}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							label := material.Body1(th, i18n.Traffic.Value())
+							label.Font.Weight = font.SemiBold
+							return label.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return p.layoutRanges(gtx, th)
+						}),
+					)
+				}),
+				layout.Rigid(layout.Spacer{Height: 8}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Alignment: layout.Middle,
+					}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return legend(gtx, th, chartInputColor, i18n.Inbound.Value())
+						}),
+						layout.Rigid(layout.Spacer{Width: 16}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return legend(gtx, th, chartOutputColor, i18n.Outbound.Value())
+						}),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return layout.E.Layout(gtx, material.Caption(th, fmt.Sprintf("%s/s", formatBytes(max))).Layout)
+						}),
+					)
+				}),
+				layout.Rigid(layout.Spacer{Height: 8}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					size := image.Pt(gtx.Constraints.Max.X, gtx.Dp(160))
+					gtx.Constraints = layout.Exact(size)
+
+					bounds := clip.Rect{Max: size}
+					paint.FillShape(gtx.Ops, theme.Current().ListBg, bounds.Op())
+
+					if max == 0 {
+						return layout.Center.Layout(gtx, material.Caption(th, i18n.NoData.Value()).Layout)
+					}
+
+					start := time.Now().Add(-d)
+					drawLine(gtx, size, samples, start, d, resolution, max, chartInputColor,
+						func(s history.Sample) uint64 { return s.InputRateBytes })
+					drawLine(gtx, size, samples, start, d, resolution, max, chartOutputColor,
+						func(s history.Sample) uint64 { return s.OutputRateBytes })
+
+					return layout.Dimensions{Size: size}
+				}),
+				layout.Rigid(layout.Spacer{Height: 4}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Spacing: layout.SpaceBetween,
+					}.Layout(gtx,
+						layout.Rigid(material.Caption(th, "-"+p.ranges[p.selected].name).Layout),
+						layout.Rigid(material.Caption(th, i18n.Now.Value()).Layout),
+					)
+				}),
+			)
+		})
+	})
+}
+
+func (p *TrafficChart) layoutRanges(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	children := make([]layout.FlexChild, 0, len(p.ranges))
+	for i := range p.ranges {
+		r := &p.ranges[i]
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: 4}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(th, &r.btn, r.name)
+				btn.TextSize = 12
+				btn.Inset = layout.Inset{Top: 4, Bottom: 4, Left: 8, Right: 8}
+				if i != p.selected {
+					btn.Background = theme.Current().ItemBg
+					btn.Color = th.Fg
+				}
+				return btn.Layout(gtx)
+			})
+		}))
+	}
+	return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+}
+
+func legend(gtx layout.Context, th *material.Theme, c color.NRGBA, text string) layout.Dimensions {
+	return layout.Flex{
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			size := image.Pt(gtx.Dp(12), gtx.Dp(3))
+			paint.FillShape(gtx.Ops, c, clip.Rect{Max: size}.Op())
+			return layout.Dimensions{Size: size}
+		}),
+		layout.Rigid(layout.Spacer{Width: 4}.Layout),
+		layout.Rigid(material.Caption(th, text).Layout),
+	)
+}
+
+// drawLine strokes the values of the samples, the line is broken where samples are missing.
+func drawLine(gtx layout.Context, size image.Point, samples []history.Sample, start time.Time, d, resolution time.Duration,
+	max uint64, c color.NRGBA, value func(s history.Sample) uint64) {
+	if len(samples) == 0 {
+		return
+	}
+
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+
+	w, h := float32(size.X), float32(size.Y)
+	point := func(s history.Sample) (float32, float32) {
+		x := float32(s.Time.Sub(start)) / float32(d) * w
+		y := h - float32(value(s))/float32(max)*(h-2) - 1
+		return x, y
+	}
+
+	var path clip.Path
+	path.Begin(gtx.Ops)
+
+	x, y := point(samples[0])
+	path.MoveTo(f32.Pt(x, y))
+	prev := samples[0].Time
+	for _, s := range samples[1:] {
+		x, y := point(s)
+		if s.Time.Sub(prev) > 2*resolution {
+			path.MoveTo(f32.Pt(x, y))
+		} else {
+			path.LineTo(f32.Pt(x, y))
+		}
+		prev = s.Time
+	}
+
+	paint.FillShape(gtx.Ops, c, clip.Stroke{
+		Path:  path.End(),
+		Width: float32(gtx.Dp(unit.Dp(1.5))),
+	}.Op())
+}
+
+var (
+	byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+func formatBytes(n uint64) string {
+	v := float64(n)
+	i := 0
+	for v >= 1024 && i < len(byteUnits)-1 {
+		v /= 1024
+		i++
+	}
+	v = float64(int64(v*100)) / 100
+	return fmt.Sprintf("%s %s", strconv.FormatFloat(v, 'f', -1, 64), byteUnits[i])
+}
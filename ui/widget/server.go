@@ -0,0 +1,65 @@
+package widget
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/widget/material"
+	"github.com/go-gost/gost.plus/server"
+	"github.com/go-gost/gost.plus/ui/i18n"
+)
+
+// ServerTunnelList lists the tunnels connected to the built-in tunnel server.
+func ServerTunnelList(gtx layout.Context, th *material.Theme, tunnels []server.Tunnel) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(th, i18n.ConnectedTunnels.Value())
+			label.Font.Weight = font.SemiBold
+			return label.Layout(gtx)
+		}),
+	}
+	if len(tunnels) == 0 {
+		children = append(children,
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+			layout.Rigid(material.Body2(th, i18n.NoConnectedTunnels.Value()).Layout),
+		)
+	}
+	for _, t := range tunnels {
+		children = append(children,
+			layout.Rigid(layout.Spacer{Height: 8}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layoutServerTunnel(gtx, th, t)
+			}),
+		)
+	}
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx, children...)
+}
+
+func layoutServerTunnel(gtx layout.Context, th *material.Theme, t server.Tunnel) layout.Dimensions {
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		layout.Rigid(material.Body2(th, t.ID).Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if len(t.Hostnames) == 0 {
+				return layout.Dimensions{}
+			}
+			return material.Caption(th, strings.Join(t.Hostnames, ", ")).Layout(gtx)
+		}),
+		layout.Rigid(material.Caption(th, fmt.Sprintf("%s %d  %s %s",
+			i18n.Connectors.Value(), len(t.Connectors),
+			i18n.ConnectedFor.Value(), time.Since(t.ConnectedAt).Truncate(time.Second),
+		)).Layout),
+		layout.Rigid(material.Caption(th, fmt.Sprintf("%s %d/%d  %s %d  %s %s / %s %s",
+			i18n.Connections.Value(), t.Stats.CurrentConns, t.Stats.TotalConns,
+			i18n.Errors.Value(), t.Stats.TotalErrs,
+			i18n.Inbound.Value(), formatBytes(t.Stats.InputBytes),
+			i18n.Outbound.Value(), formatBytes(t.Stats.OutputBytes),
+		)).Layout),
+	)
+}